@@ -20,6 +20,11 @@ type FilerBase struct {
 	Username         string `yaml:"username"`
 	Password         string `yaml:"password"`
 	Version          string `yaml:"version"`
+	TLSVerify        bool   `yaml:"tls_verify"`
+	CAFile           string `yaml:"ca_file"`
+	CertFile         string `yaml:"cert_file"`
+	KeyFile          string `yaml:"key_file"`
+	ServerName       string `yaml:"server_name"`
 }
 
 type Filer struct {
@@ -28,7 +33,13 @@ type Filer struct {
 }
 
 func NewFiler(f FilerBase) (Filer, error) {
-	c, err := netapp.NewClient(f.Host, f.Username, f.Password, f.Version)
+	c, err := netapp.NewClient(f.Host, f.Username, f.Password, f.Version, netapp.TLSOptions{
+		Verify:     f.TLSVerify,
+		CAFile:     f.CAFile,
+		CertFile:   f.CertFile,
+		KeyFile:    f.KeyFile,
+		ServerName: f.ServerName,
+	})
 	if err != nil {
 		return Filer{}, err
 	}
@@ -62,7 +73,8 @@ func loadFilerFromFile(fileName string) (filers []Filer, err error) {
 		return nil, err
 	}
 	for _, f := range filerInfos {
-		if f.Username == "" || f.Password == "" {
+		// filers authenticated by client certificate don't need credentials
+		if (f.Username == "" || f.Password == "") && f.CertFile == "" {
 			username, password := getAuthFromEnv()
 			f.Username = username
 			f.Password = password
@@ -70,9 +82,12 @@ func loadFilerFromFile(fileName string) (filers []Filer, err error) {
 		if f.Version == "" {
 			f.Version = netappApiVersion
 		}
+		// a filer with an invalid tls config is skipped, so that the other
+		// filers are still loaded
 		ff, err := NewFiler(*f)
 		if err != nil {
-			return nil, err
+			log.WithFields(log.Fields{"Name": f.Name, "Host": f.Host}).WithError(err).Error("load filer failed")
+			continue
 		}
 		filers = append(filers, ff)
 	}
@@ -87,7 +102,20 @@ func loadFilerFromEnv() (Filer, error) {
 	password := os.Getenv("NETAPP_PASSWORD")
 	az := os.Getenv("NETAPP_AZ")
 	version := getEnvWithDefaultValue("Netapp_API_VERSION", netappApiVersion)
-	return NewFiler(FilerBase{name, host, az, pattern, username, password, version})
+	return NewFiler(FilerBase{
+		Name:             name,
+		Host:             host,
+		AvailabilityZone: az,
+		AggregatePattern: pattern,
+		Username:         username,
+		Password:         password,
+		Version:          version,
+		TLSVerify:        os.Getenv("NETAPP_TLS_VERIFY") == "true",
+		CAFile:           os.Getenv("NETAPP_CA_FILE"),
+		CertFile:         os.Getenv("NETAPP_CERT_FILE"),
+		KeyFile:          os.Getenv("NETAPP_KEY_FILE"),
+		ServerName:       os.Getenv("NETAPP_SERVER_NAME"),
+	})
 }
 
 func getAuthFromEnv() (username, password string) {
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadFilerFromFileSkipsInvalidFiler(t *testing.T) {
+	dir := t.TempDir()
+	configFile := filepath.Join(dir, "filers.yaml")
+	config := `
+- name: filer-1
+  host: filer-1.example.com
+  availability_zone: az-a
+  username: user
+  password: password
+- name: filer-2
+  host: filer-2.example.com
+  availability_zone: az-a
+  tls_verify: true
+  ca_file: ` + filepath.Join(dir, "missing.pem") + `
+  server_name: filer.example.com
+- name: filer-3
+  host: filer-3.example.com
+  availability_zone: az-a
+  tls_verify: true
+  server_name: filer.example.com
+`
+	if err := ioutil.WriteFile(configFile, []byte(config), 0600); err != nil {
+		t.Fatal(err)
+	}
+	filers, err := loadFilerFromFile(configFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, f := range filers {
+		names = append(names, f.Name)
+	}
+	if len(names) != 2 || names[0] != "filer-1" || names[1] != "filer-3" {
+		t.Errorf("got filers %v, want [filer-1 filer-3]", names)
+	}
+}
@@ -1,18 +1,18 @@
 package main
 
 import (
-	"context"
-	"errors"
 	"fmt"
-	"net"
 	"net/http"
 	"os"
-	"strconv"
+	"os/signal"
+	"sync"
+	"syscall"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/sapcc/netapp-api-exporter/pkg/collector"
+	"github.com/sapcc/netapp-api-exporter/pkg/netapp"
 	"gopkg.in/alecthomas/kingpin.v2"
 
 	log "github.com/sirupsen/logrus"
@@ -22,100 +22,140 @@ var (
 	configFile        = kingpin.Flag("config", "Config file").Short('c').Default("./netapp-filers.yaml").String()
 	listenAddress     = kingpin.Flag("listen", "Listen address").Short('l').Default("0.0.0.0").String()
 	debug             = kingpin.Flag("debug", "Debug mode").Short('d').Bool()
+	configWatchPeriod = kingpin.Flag("config-watch-period", "Period of checking the config file for changes").Default("30s").Duration()
 	volumeFetchPeriod = kingpin.Flag("volume-fetch-period", "Period of asynchronously fetching volumes").Short('v').Default("2m").Duration()
 	disableAggregate  = kingpin.Flag("no-aggregate", "Disable aggregate collector").Bool()
 	disableVolume     = kingpin.Flag("no-volume", "Disable volume collector").Bool()
 	disableSystem     = kingpin.Flag("no-system", "Disable system collector").Bool()
+	disableSnapmirror = kingpin.Flag("no-snapmirror", "Disable snapmirror collector").Bool()
+	disableVolumePerf = kingpin.Flag("no-volume-perf", "Disable volume performance collector").Bool()
+	disableEnv        = kingpin.Flag("no-environment", "Disable environment sensor collector").Bool()
+	disableDisk       = kingpin.Flag("no-disk", "Disable disk collector").Bool()
+	disableFailover   = kingpin.Flag("no-failover", "Disable storage failover collector").Bool()
+	disableQuota      = kingpin.Flag("no-quota", "Disable quota collector").Bool()
+	disableQtree      = kingpin.Flag("no-qtree", "Disable qtree collector").Bool()
+	disableFootprint  = kingpin.Flag("no-volume-footprint", "Disable volume footprint collector").Bool()
+	disableSnapshot   = kingpin.Flag("no-snapshot", "Disable snapshot collector").Bool()
+	disableNetwork    = kingpin.Flag("no-network", "Disable network port and interface collector").Bool()
+	disableHealth     = kingpin.Flag("no-health", "Disable system health alert collector").Bool()
+	disableCert       = kingpin.Flag("no-certificate", "Disable certificate collector").Bool()
+	disableLun        = kingpin.Flag("no-lun", "Disable lun collector").Bool()
+	disableFcp        = kingpin.Flag("no-fcp", "Disable fc adapter collector").Bool()
+	disableVserver    = kingpin.Flag("no-vserver", "Disable vserver collector").Bool()
+	disableQos        = kingpin.Flag("no-qos", "Disable qos policy group collector").Bool()
+	disableJob        = kingpin.Flag("no-job", "Disable job collector").Bool()
+	disableVolumeMove = kingpin.Flag("no-volume-move", "Disable volume move collector").Bool()
+	snapshotDetails   = kingpin.Flag("snapshot-details", "Export metrics of every single snapshot").Bool()
 
-	DNSErrorCounter = prometheus.NewCounterVec(
-		prometheus.CounterOpts{
-			Name: "netapp_filer_dns_error",
-			Help: "hostname not resolved",
-		},
-		[]string{"host"},
-	)
-	AuthenticationErrorCounter = prometheus.NewCounterVec(
-		prometheus.CounterOpts{
-			Name: "netapp_filer_authentication_error",
-			Help: "access netapp filer failed with 401",
-		},
-		[]string{"host"},
-	)
-	TimeoutErrorCounter = prometheus.NewCounterVec(
-		prometheus.CounterOpts{
-			Name: "netapp_filer_timeout_error",
-			Help: "access netapp filer timeout",
-		},
-		[]string{"host"},
-	)
-	UnknownErrorCounter = prometheus.NewCounterVec(
-		prometheus.CounterOpts{
-			Name: "netapp_filer_unknown_error",
-			Help: "check filer failed with unknown error",
-		},
-		[]string{"host"},
-	)
+	// errors of the filer checks of the supervisors; probes have their own
+	filerCheckErrors = newCheckErrorCounters()
 )
 
+// checkErrorCounters count the failed filer checks by error reason and host.
+type checkErrorCounters struct {
+	dns     *prometheus.CounterVec
+	auth    *prometheus.CounterVec
+	timeout *prometheus.CounterVec
+	unknown *prometheus.CounterVec
+}
+
+func newCheckErrorCounters() *checkErrorCounters {
+	return &checkErrorCounters{
+		dns: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "netapp_filer_dns_error",
+				Help: "hostname not resolved",
+			},
+			[]string{"host"},
+		),
+		auth: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "netapp_filer_authentication_error",
+				Help: "access netapp filer failed with 401",
+			},
+			[]string{"host"},
+		),
+		timeout: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "netapp_filer_timeout_error",
+				Help: "access netapp filer timeout",
+			},
+			[]string{"host"},
+		),
+		unknown: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "netapp_filer_unknown_error",
+				Help: "check filer failed with unknown error",
+			},
+			[]string{"host"},
+		),
+	}
+}
+
+func (c *checkErrorCounters) collectors() []prometheus.Collector {
+	return []prometheus.Collector{c.dns, c.auth, c.timeout, c.unknown}
+}
+
+func (c *checkErrorCounters) count(host string, err error) {
+	switch netapp.ErrorReason(err) {
+	case netapp.ErrorReasonDNS:
+		c.dns.WithLabelValues(host).Inc()
+	case netapp.ErrorReasonAuth:
+		c.auth.WithLabelValues(host).Inc()
+	case netapp.ErrorReasonTimeout:
+		c.timeout.WithLabelValues(host).Inc()
+	default:
+		c.unknown.WithLabelValues(host).Inc()
+	}
+}
+
 func main() {
-	var filers map[string]Filer
+	// the flags are parsed in main instead of init, so that the test binary's
+	// flags are not parsed as the exporter's
+	kingpin.Parse()
+	initLogging()
 
 	// new prometheus registry and register global collectors
 	reg := prometheus.NewPedanticRegistry()
 	reg.MustRegister(prometheus.NewGoCollector())
-	reg.MustRegister(DNSErrorCounter)
-	reg.MustRegister(AuthenticationErrorCounter)
-	reg.MustRegister(TimeoutErrorCounter)
-	reg.MustRegister(UnknownErrorCounter)
+	reg.MustRegister(filerCheckErrors.collectors()...)
+
+	probes := newProbeHandler()
 
-	// load filers from configuration and register new colloector for new filer
+	// load filers from configuration and reconcile the registered filers
+	// whenever the config file changes or SIGHUP is received
 	go func() {
+		var loadedFilers []Filer
+		filers := make(map[string]*registeredFiler)
 		initLoadCounter := 0
 		initLoadCh := make(chan bool, 1)
-		reloadTicker := time.NewTicker(5 * time.Minute)
-		defer reloadTicker.Stop()
+		hupCh := make(chan os.Signal, 1)
+		signal.Notify(hupCh, syscall.SIGHUP)
+		watchCh := watchConfigFile(*configFile, *configWatchPeriod)
 
 		for {
 			ff, err := loadFilers(*configFile)
 			if err != nil {
+				// keep the running filers if the config file could not be loaded
 				log.WithError(err).Error("load filers failed")
 				// retry initial loading config file quickly for 10 times
-				if initLoadCounter < 10 {
+				if loadedFilers == nil && initLoadCounter < 10 {
 					time.Sleep(10 * time.Second)
 					initLoadCh <- true
 				}
 			} else {
-				for _, f := range ff {
-					if _, ok := filers[f.Host]; ok {
-						continue
-					}
-					l := log.WithFields(log.Fields{
-						"Name":             f.Name,
-						"Host":             f.Host,
-						"AvailabilityZone": f.AvailabilityZone,
-						"AggregatePattern": f.AggregatePattern,
-					})
-					l.Info("check filer")
-					if !checkFiler(f, l) {
-						continue
-					}
-					l.Info("register filer")
-					err = registerFiler(reg, f)
-					if err != nil {
-						l.Error(err)
-						continue
-					}
-					if filers == nil {
-						filers = make(map[string]Filer)
-					}
-					filers[f.Host] = f
-				}
+				loadedFilers = ff
+				probes.SetFilers(ff)
+				reconcileFilers(reg, filers, ff)
 			}
 
 			select {
 			case <-initLoadCh:
 				initLoadCounter += 1
-			case <-reloadTicker.C:
+			case <-hupCh:
+				log.Info("SIGHUP received, reload filers")
+			case <-watchCh:
+				log.Info("config file changed, reload filers")
 			}
 		}
 	}()
@@ -123,69 +163,317 @@ func main() {
 	port := "9108"
 	addr := *listenAddress + ":" + port
 	http.Handle("/metrics", promhttp.HandlerFor(reg, promhttp.HandlerOpts{}))
+	http.Handle("/probe", probes)
 	log.WithField("address", fmt.Sprintf("http://%s/metrics", addr)).Info("exporting metrics")
 	log.Fatal(http.ListenAndServe(addr, nil))
 }
 
-func checkFiler(f Filer, l *log.Entry) bool {
-	var dnsError *net.DNSError
-	status, err := f.Client.CheckCluster()
-	l = l.WithField("status", strconv.Itoa(status))
-	switch status {
-	case 200, 201, 202, 204, 205, 206:
-	case 401:
-		AuthenticationErrorCounter.WithLabelValues(f.Host).Inc()
-		l.Error("check filer failed: authentication error")
-		return false
-	default:
+// checkFiler checks whether the filer is reachable, reports the result to the
+// filer status and counts the errors.
+func checkFiler(f Filer, status *collector.FilerStatus, errorCounters *checkErrorCounters, l *log.Entry) bool {
+	err := f.Client.CheckCluster()
+	if err == nil {
+		status.ReportCheck(nil)
+		return true
+	}
+	l.WithError(err).Error("check filer failed")
+	status.ReportCheck(err)
+	errorCounters.count(f.Host, err)
+	return false
+}
+
+// registeredFiler keeps the collectors registered for a filer, so that they
+// can be unregistered when the filer is removed from or changed in the config.
+// The status collector is registered for every filer, the other collectors
+// are registered by the filer's supervisor once the filer is reachable. The
+// mutex guards the registration against a supervisor still finishing a check
+// after the filer has been unregistered.
+type registeredFiler struct {
+	Filer
+	registerer      prometheus.Registerer
+	status          *collector.FilerStatus
+	collectors      []prometheus.Collector
+	volumeCollector *collector.VolumeCollector
+	ready           bool
+	stopped         bool
+	stopCh          chan struct{}
+	mux             sync.Mutex
+}
+
+// reconcileFilers registers the loaded filers which are not yet registered, and
+// unregisters the filers which are removed from the config or whose definition
+// has changed. Changed filers are registered again with the new definition.
+func reconcileFilers(reg prometheus.Registerer, filers map[string]*registeredFiler, loadedFilers []Filer) {
+	loaded := make(map[string]Filer, len(loadedFilers))
+	for _, f := range loadedFilers {
+		loaded[f.Name] = f
+	}
+	for name, rf := range filers {
+		if f, ok := loaded[name]; ok && f.FilerBase == rf.FilerBase {
+			continue
+		}
+		log.WithFields(log.Fields{"Name": rf.Name, "Host": rf.Host}).Info("unregister filer")
+		unregisterFiler(rf)
+		delete(filers, name)
+	}
+	for _, f := range loadedFilers {
+		l := log.WithFields(log.Fields{
+			"Name":             f.Name,
+			"Host":             f.Host,
+			"AvailabilityZone": f.AvailabilityZone,
+			"AggregatePattern": f.AggregatePattern,
+		})
+		if _, ok := filers[f.Name]; ok {
+			continue
+		}
+		rf, err := registerFiler(reg, f)
 		if err != nil {
-			l.WithError(err).Error("check filer failed")
-			if errors.As(err, &dnsError) {
-				DNSErrorCounter.WithLabelValues(f.Host).Inc()
-			} else if errors.Is(err, context.DeadlineExceeded) {
-				TimeoutErrorCounter.WithLabelValues(f.Host).Inc()
-			} else {
-				UnknownErrorCounter.WithLabelValues(f.Host).Inc()
-			}
-		} else {
-			UnknownErrorCounter.WithLabelValues(f.Host).Inc()
-			l.Error("check filer failed")
+			l.Error(err)
+			continue
 		}
-		return false
+		filers[f.Name] = rf
+		go rf.supervise(l)
 	}
-	return true
 }
 
-func registerFiler(reg prometheus.Registerer, f Filer) error {
+// collectorFlags maps the collector names, which are also the names of the probe
+// modules, to the flags disabling them.
+var collectorFlags = map[string]*bool{
+	"aggregate":        disableAggregate,
+	"volume":           disableVolume,
+	"volume-perf":      disableVolumePerf,
+	"volume-footprint": disableFootprint,
+	"system":           disableSystem,
+	"snapmirror":       disableSnapmirror,
+	"environment":      disableEnv,
+	"disk":             disableDisk,
+	"failover":         disableFailover,
+	"quota":            disableQuota,
+	"qtree":            disableQtree,
+	"snapshot":         disableSnapshot,
+	"network":          disableNetwork,
+	"health":           disableHealth,
+	"certificate":      disableCert,
+	"lun":              disableLun,
+	"fcp":              disableFcp,
+	"vserver":          disableVserver,
+	"qos":              disableQos,
+	"job":              disableJob,
+	"volume-move":      disableVolumeMove,
+}
+
+func enabledByFlags(name string) bool {
+	return !*collectorFlags[name]
+}
+
+// registerFiler registers the status collector of the filer.
+func registerFiler(reg prometheus.Registerer, f Filer) (*registeredFiler, error) {
 	if f.Name == "" {
-		return fmt.Errorf("Filer.Name not set")
+		return nil, fmt.Errorf("Filer.Name not set")
 	}
 	if f.AvailabilityZone == "" {
-		return fmt.Errorf("Filer.AvailabilityZone not set")
+		return nil, fmt.Errorf("Filer.AvailabilityZone not set")
+	}
+	rf := &registeredFiler{
+		Filer:      f,
+		registerer: prometheus.WrapRegistererWith(filerLabels(f), reg),
+		status:     collector.NewFilerStatus(),
+		stopCh:     make(chan struct{}),
+	}
+	statusCollector := collector.NewFilerStatusCollector(rf.status)
+	if err := rf.registerer.Register(statusCollector); err != nil {
+		return nil, err
 	}
-	extraLabels := prometheus.Labels{
+	rf.collectors = append(rf.collectors, statusCollector)
+	return rf, nil
+}
+
+// registerCollectors registers the collectors enabled by flags, unless the
+// filer has been unregistered in the meantime. If one of them fails to
+// register, the ones registered before are unregistered again.
+func (rf *registeredFiler) registerCollectors() error {
+	defer rf.mux.Unlock()
+	rf.mux.Lock()
+
+	if rf.stopped {
+		return nil
+	}
+	collectors, volumeCollector := newCollectors(rf.Filer, rf.status, enabledByFlags, *volumeFetchPeriod)
+	for i, c := range collectors {
+		collectors[i] = upCollector{c, rf.status}
+	}
+	for i, c := range collectors {
+		if err := rf.registerer.Register(c); err != nil {
+			for _, c := range collectors[:i] {
+				rf.registerer.Unregister(c)
+			}
+			if volumeCollector != nil {
+				volumeCollector.Stop()
+			}
+			return err
+		}
+	}
+	rf.collectors = append(rf.collectors, collectors...)
+	rf.volumeCollector = volumeCollector
+	rf.ready = true
+	return nil
+}
+
+// newCollectors returns the enabled collectors of the filer, which report their
+// fetches to the status. The volume collector is returned separately, since it
+// has to be stopped or refreshed by the caller; it is nil if no enabled
+// collector needs it.
+func newCollectors(f Filer, status *collector.FilerStatus, enabled func(name string) bool, volumeFetchPeriod time.Duration) (collectors []prometheus.Collector, volumeCollector *collector.VolumeCollector) {
+	if enabled("aggregate") {
+		collectors = append(collectors,
+			collector.NewAggregateCollector(f.Client, f.Name, status, f.AggregatePattern))
+	}
+	// volume perf and volume footprint collectors use the cached volumes for
+	// labeling, the snapshot collector to export volumes without snapshots
+	if enabled("volume") || enabled("volume-perf") || enabled("volume-footprint") || enabled("snapshot") {
+		volumeCollector = collector.NewVolumeCollector(f.Client, f.Name, status, volumeFetchPeriod)
+	}
+	if enabled("volume") {
+		collectors = append(collectors, volumeCollector)
+	}
+	if enabled("volume-perf") {
+		collectors = append(collectors,
+			collector.NewVolumePerfCollector(f.Client, f.Name, status, volumeCollector))
+	}
+	if enabled("volume-footprint") {
+		collectors = append(collectors,
+			collector.NewVolumeFootprintCollector(f.Client, f.Name, status, volumeCollector))
+	}
+	if enabled("system") {
+		collectors = append(collectors,
+			collector.NewSystemCollector(f.Client, f.Name, status))
+	}
+	if enabled("snapmirror") {
+		collectors = append(collectors,
+			collector.NewSnapmirrorCollector(f.Client, f.Name, status))
+	}
+	if enabled("environment") {
+		collectors = append(collectors,
+			collector.NewEnvironmentCollector(f.Client, f.Name, status))
+	}
+	if enabled("disk") {
+		collectors = append(collectors,
+			collector.NewDiskCollector(f.Client, f.Name, status))
+	}
+	if enabled("failover") {
+		collectors = append(collectors,
+			collector.NewFailoverCollector(f.Client, f.Name, status))
+	}
+	if enabled("quota") {
+		collectors = append(collectors,
+			collector.NewQuotaCollector(f.Client, f.Name, status))
+	}
+	if enabled("qtree") {
+		collectors = append(collectors,
+			collector.NewQtreeCollector(f.Client, f.Name, status))
+	}
+	if enabled("snapshot") {
+		collectors = append(collectors,
+			collector.NewSnapshotCollector(f.Client, f.Name, status, volumeCollector, *snapshotDetails))
+	}
+	if enabled("network") {
+		collectors = append(collectors,
+			collector.NewNetworkCollector(f.Client, f.Name, status))
+	}
+	if enabled("health") {
+		collectors = append(collectors,
+			collector.NewHealthCollector(f.Client, f.Name, status))
+	}
+	if enabled("certificate") {
+		collectors = append(collectors,
+			collector.NewCertificateCollector(f.Client, f.Name, status))
+	}
+	if enabled("lun") {
+		collectors = append(collectors,
+			collector.NewLunCollector(f.Client, f.Name, status))
+	}
+	if enabled("fcp") {
+		collectors = append(collectors,
+			collector.NewFcpCollector(f.Client, f.Name, status))
+	}
+	if enabled("vserver") {
+		collectors = append(collectors,
+			collector.NewVserverCollector(f.Client, f.Name, status))
+	}
+	if enabled("qos") {
+		collectors = append(collectors,
+			collector.NewQosCollector(f.Client, f.Name, status))
+	}
+	if enabled("job") {
+		collectors = append(collectors,
+			collector.NewJobCollector(f.Client, f.Name, status))
+	}
+	if enabled("volume-move") {
+		collectors = append(collectors,
+			collector.NewVolumeMoveCollector(f.Client, f.Name, status))
+	}
+	return
+}
+
+func filerLabels(f Filer) prometheus.Labels {
+	return prometheus.Labels{
 		"filer":             f.Name,
 		"host":              f.Host,
 		"availability_zone": f.AvailabilityZone,
 	}
-	if !*disableAggregate {
-		prometheus.WrapRegistererWith(extraLabels, reg).MustRegister(
-			collector.NewAggregateCollector(f.Client, f.Name, f.AggregatePattern))
-	}
-	if !*disableVolume {
-		prometheus.WrapRegistererWith(extraLabels, reg).MustRegister(
-			collector.NewVolumeCollector(f.Client, f.Name, *volumeFetchPeriod))
+}
+
+// unregisterFiler stops the supervisor of the filer, unregisters its
+// collectors and stops fetching volumes in the background. The filer status
+// is dropped together with the registration and its status collector. It does
+// not wait for the supervisor to finish a running check, so that reloading
+// the other filers is not delayed.
+func unregisterFiler(rf *registeredFiler) {
+	defer rf.mux.Unlock()
+	rf.mux.Lock()
+
+	close(rf.stopCh)
+	rf.stopped = true
+	for _, c := range rf.collectors {
+		rf.registerer.Unregister(c)
 	}
-	if !*disableSystem {
-		prometheus.WrapRegistererWith(extraLabels, reg).MustRegister(
-			collector.NewSystemCollector(f.Client, f.Name))
+	if rf.volumeCollector != nil {
+		rf.volumeCollector.Stop()
 	}
-	return nil
 }
 
-func init() {
-	kingpin.Parse()
+// watchConfigFile notifies about changes of the config file. The modification
+// time is polled instead of using inotify, since the file may be replaced as a
+// whole, e.g. when it is mounted from a Kubernetes ConfigMap.
+func watchConfigFile(fileName string, period time.Duration) <-chan bool {
+	ch := make(chan bool)
+	if fileName == "" || period <= 0 {
+		return ch
+	}
+	go func() {
+		var lastModTime time.Time
+		var lastSize int64
+		if fi, err := os.Stat(fileName); err == nil {
+			lastModTime, lastSize = fi.ModTime(), fi.Size()
+		}
+		ticker := time.NewTicker(period)
+		defer ticker.Stop()
+		for range ticker.C {
+			fi, err := os.Stat(fileName)
+			if err != nil {
+				continue
+			}
+			if !fi.ModTime().Equal(lastModTime) || fi.Size() != lastSize {
+				lastModTime, lastSize = fi.ModTime(), fi.Size()
+				ch <- true
+			}
+		}
+	}()
+	return ch
+}
 
+// initLogging configures the logger after the flags have been parsed.
+func initLogging() {
 	log.SetOutput(os.Stdout)
 	log.SetFormatter(&log.TextFormatter{})
 	if *debug || os.Getenv("DEV") == "1" {
@@ -12,10 +12,12 @@ import (
 type AggregateCollector struct {
 	client               *netapp.Client
 	filerName            string
+	status               *FilerStatus
 	aggregatePattern     string
 	aggregateMetrics     []AggregateMetric
 	scrapeCounter        prometheus.Counter
 	scrapeFailureCounter prometheus.Counter
+	spaceFailureCounter  prometheus.Counter
 	scrapeDurationGauge  prometheus.Gauge
 }
 
@@ -23,9 +25,11 @@ type AggregateMetric struct {
 	desc      *prometheus.Desc
 	valueType prometheus.ValueType
 	getterFn  func(aggr *netapp.Aggregate) float64
+	// only exported if the space breakdown has been fetched
+	spaceBreakdown bool
 }
 
-func NewAggregateCollector(client *netapp.Client, filerName, aggrPattern string) *AggregateCollector {
+func NewAggregateCollector(client *netapp.Client, filerName string, status *FilerStatus, aggrPattern string) *AggregateCollector {
 	aggrLabels := []string{"node", "aggregate"}
 	aggrMetrics := []AggregateMetric{
 		{
@@ -98,6 +102,69 @@ func NewAggregateCollector(client *netapp.Client, filerName, aggrPattern string)
 				}
 				return 0.0
 			},
+		}, {
+			desc: prometheus.NewDesc(
+				"netapp_aggregate_volume_footprints_bytes",
+				"Netapp Aggregate Metrics: space used by volume footprints",
+				aggrLabels,
+				nil),
+			valueType:      prometheus.GaugeValue,
+			getterFn:       func(m *netapp.Aggregate) float64 { return m.VolumeFootprints },
+			spaceBreakdown: true,
+		}, {
+			desc: prometheus.NewDesc(
+				"netapp_aggregate_metadata_bytes",
+				"Netapp Aggregate Metrics: space used by aggregate metadata",
+				aggrLabels,
+				nil),
+			valueType:      prometheus.GaugeValue,
+			getterFn:       func(m *netapp.Aggregate) float64 { return m.AggregateMetadata },
+			spaceBreakdown: true,
+		}, {
+			desc: prometheus.NewDesc(
+				"netapp_aggregate_snapshot_reserve_bytes",
+				"Netapp Aggregate Metrics: size of the aggregate snapshot reserve",
+				aggrLabels,
+				nil),
+			valueType:      prometheus.GaugeValue,
+			getterFn:       func(m *netapp.Aggregate) float64 { return m.SnapshotReserve },
+			spaceBreakdown: true,
+		}, {
+			desc: prometheus.NewDesc(
+				"netapp_aggregate_snapshot_reserve_unusable_bytes",
+				"Netapp Aggregate Metrics: snapshot reserve which is not usable",
+				aggrLabels,
+				nil),
+			valueType:      prometheus.GaugeValue,
+			getterFn:       func(m *netapp.Aggregate) float64 { return m.SnapshotReserveUnusable },
+			spaceBreakdown: true,
+		}, {
+			desc: prometheus.NewDesc(
+				"netapp_aggregate_inactive_user_data_bytes",
+				"Netapp Aggregate Metrics: inactive user data in the performance tier",
+				aggrLabels,
+				nil),
+			valueType:      prometheus.GaugeValue,
+			getterFn:       func(m *netapp.Aggregate) float64 { return m.PerformanceTierInactiveUserData },
+			spaceBreakdown: true,
+		}, {
+			desc: prometheus.NewDesc(
+				"netapp_aggregate_dedupe_saved_bytes",
+				"Netapp Aggregate Metrics: space saved by deduplication and compression",
+				aggrLabels,
+				nil),
+			valueType:      prometheus.GaugeValue,
+			getterFn:       func(m *netapp.Aggregate) float64 { return m.SisSpaceSaved },
+			spaceBreakdown: true,
+		}, {
+			desc: prometheus.NewDesc(
+				"netapp_aggregate_data_compaction_saved_bytes",
+				"Netapp Aggregate Metrics: space saved by data compaction",
+				aggrLabels,
+				nil),
+			valueType:      prometheus.GaugeValue,
+			getterFn:       func(m *netapp.Aggregate) float64 { return m.DataCompactionSpaceSaved },
+			spaceBreakdown: true,
 		},
 	}
 	scrapeDurationGauge := prometheus.NewGauge(
@@ -118,14 +185,22 @@ func NewAggregateCollector(client *netapp.Client, filerName, aggrPattern string)
 			Help: "number of failures for fetching aggregates from filer",
 		},
 	)
+	spaceFailureCounter := prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "netapp_aggregate_space_scrape_failure_total",
+			Help: "number of failures for fetching the aggregate space breakdown from filer",
+		},
+	)
 	return &AggregateCollector{
 		client:               client,
 		filerName:            filerName,
+		status:               status,
 		aggregatePattern:     aggrPattern,
 		aggregateMetrics:     aggrMetrics,
 		scrapeDurationGauge:  scrapeDurationGauge,
 		scrapeCounter:        scrapeCounter,
 		scrapeFailureCounter: scrapeFailureCounter,
+		spaceFailureCounter:  spaceFailureCounter,
 	}
 }
 
@@ -135,6 +210,7 @@ func (c *AggregateCollector) Describe(ch chan<- *prometheus.Desc) {
 	}
 	ch <- c.scrapeCounter.Desc()
 	ch <- c.scrapeFailureCounter.Desc()
+	ch <- c.spaceFailureCounter.Desc()
 	ch <- c.scrapeDurationGauge.Desc()
 }
 
@@ -157,20 +233,33 @@ func (c *AggregateCollector) Collect(ch chan<- prometheus.Metric) {
 
 		labels := []string{aggr.OwnerName, aggr.Name}
 		for _, m := range c.aggregateMetrics {
+			if m.spaceBreakdown && !aggr.HasSpaceBreakdown {
+				continue
+			}
 			ch <- prometheus.MustNewConstMetric(m.desc, m.valueType, m.getterFn(aggr), labels...)
 		}
 	}
 	c.scrapeCounter.Collect(ch)
 	c.scrapeFailureCounter.Collect(ch)
+	c.spaceFailureCounter.Collect(ch)
 	c.scrapeDurationGauge.Collect(ch)
 }
 
 func (c *AggregateCollector) Fetch() []*netapp.Aggregate {
 	start := time.Now()
 	aggregates, err := c.client.ListAggregates()
+	if err == nil {
+		// the aggregates are exported without the space breakdown if it
+		// cannot be fetched
+		if spaceErr := c.client.AddAggregateSpace(aggregates); spaceErr != nil {
+			log.WithField("filer", c.filerName).WithError(spaceErr).Error("list aggregate space failed")
+			c.spaceFailureCounter.Inc()
+		}
+	}
 	elapsed := time.Now().Sub(start)
 	c.scrapeCounter.Inc()
 	c.scrapeDurationGauge.Set(elapsed.Seconds())
+	c.status.reportScrape("aggregate", err)
 	if err != nil {
 		log.WithError(err).Error("list aggregates failed")
 		c.scrapeFailureCounter.Inc()
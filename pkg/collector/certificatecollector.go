@@ -0,0 +1,87 @@
+package collector
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sapcc/netapp-api-exporter/pkg/netapp"
+	log "github.com/sirupsen/logrus"
+)
+
+type CertificateCollector struct {
+	client               *netapp.Client
+	filerName            string
+	status               *FilerStatus
+	expiryDesc           *prometheus.Desc
+	scrapeCounter        prometheus.Counter
+	scrapeFailureCounter prometheus.Counter
+	scrapeDurationGauge  prometheus.Gauge
+}
+
+func NewCertificateCollector(client *netapp.Client, filerName string, status *FilerStatus) *CertificateCollector {
+	scrapeDurationGauge := prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "netapp_certificate_scrape_duration_seconds",
+			Help: "duration in seconds of fetching certificates from filer",
+		},
+	)
+	scrapeCounter := prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "netapp_certificate_scrape_total",
+			Help: "number of certificate fetches from filer",
+		},
+	)
+	scrapeFailureCounter := prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "netapp_certificate_scrape_failure_total",
+			Help: "number of failures for fetching certificates from filer",
+		},
+	)
+	return &CertificateCollector{
+		client:    client,
+		filerName: filerName,
+		status:    status,
+		expiryDesc: prometheus.NewDesc(
+			"netapp_certificate_expiry_timestamp_seconds",
+			"Netapp Certificate: expiration time",
+			[]string{"vserver", "type", "common_name", "serial_number"},
+			nil),
+		scrapeDurationGauge:  scrapeDurationGauge,
+		scrapeCounter:        scrapeCounter,
+		scrapeFailureCounter: scrapeFailureCounter,
+	}
+}
+
+func (c *CertificateCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.expiryDesc
+	ch <- c.scrapeCounter.Desc()
+	ch <- c.scrapeFailureCounter.Desc()
+	ch <- c.scrapeDurationGauge.Desc()
+}
+
+func (c *CertificateCollector) Collect(ch chan<- prometheus.Metric) {
+	certificates := c.Fetch()
+
+	for _, cert := range certificates {
+		ch <- prometheus.MustNewConstMetric(c.expiryDesc, prometheus.GaugeValue, cert.ExpirationTime,
+			cert.Vserver, cert.Type, cert.CommonName, cert.SerialNumber)
+	}
+	c.scrapeCounter.Collect(ch)
+	c.scrapeFailureCounter.Collect(ch)
+	c.scrapeDurationGauge.Collect(ch)
+}
+
+func (c *CertificateCollector) Fetch() []*netapp.Certificate {
+	start := time.Now()
+	certificates, err := c.client.ListCertificates()
+	elapsed := time.Now().Sub(start)
+	c.scrapeCounter.Inc()
+	c.scrapeDurationGauge.Set(elapsed.Seconds())
+	c.status.reportScrape("certificate", err)
+	if err != nil {
+		log.WithField("filer", c.filerName).WithError(err).Error("list certificates failed")
+		c.scrapeFailureCounter.Inc()
+		return nil
+	}
+	return certificates
+}
@@ -0,0 +1,195 @@
+package collector
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sapcc/netapp-api-exporter/pkg/netapp"
+	log "github.com/sirupsen/logrus"
+)
+
+type DiskCollector struct {
+	client               *netapp.Client
+	filerName            string
+	status               *FilerStatus
+	diskMetrics          []DiskMetric
+	diskInfoDesc         *prometheus.Desc
+	spareDisksDesc       *prometheus.Desc
+	spareZeroedDesc      *prometheus.Desc
+	spareUsableDesc      *prometheus.Desc
+	scrapeCounter        prometheus.Counter
+	scrapeFailureCounter prometheus.Counter
+	scrapeDurationGauge  prometheus.Gauge
+}
+
+type DiskMetric struct {
+	desc      *prometheus.Desc
+	valueType prometheus.ValueType
+	getterFn  func(d *netapp.Disk) float64
+}
+
+type spareDiskCount struct {
+	node       string
+	diskType   string
+	count      float64
+	zeroed     float64
+	usableSize float64
+}
+
+func NewDiskCollector(client *netapp.Client, filerName string, status *FilerStatus) *DiskCollector {
+	diskLabels := []string{"disk", "node"}
+	diskMetrics := []DiskMetric{
+		{
+			desc:      prometheus.NewDesc("netapp_disk_capacity_bytes", "Netapp Disk: physical capacity", diskLabels, nil),
+			valueType: prometheus.GaugeValue,
+			getterFn:  func(d *netapp.Disk) float64 { return d.Capacity },
+		}, {
+			desc:      prometheus.NewDesc("netapp_disk_is_failed", "Netapp Disk: is failed", diskLabels, nil),
+			valueType: prometheus.GaugeValue,
+			getterFn: func(d *netapp.Disk) float64 {
+				if d.IsFailed {
+					return 1.0
+				}
+				return 0.0
+			},
+		}, {
+			desc:      prometheus.NewDesc("netapp_disk_is_prefailed", "Netapp Disk: is prefailed", diskLabels, nil),
+			valueType: prometheus.GaugeValue,
+			getterFn: func(d *netapp.Disk) float64 {
+				if d.IsPrefailed {
+					return 1.0
+				}
+				return 0.0
+			},
+		},
+	}
+	spareLabels := []string{"node", "disk_type"}
+	scrapeDurationGauge := prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "netapp_disk_scrape_duration_seconds",
+			Help: "duration in seconds of fetching disks from filer",
+		},
+	)
+	scrapeCounter := prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "netapp_disk_scrape_total",
+			Help: "number of disk fetches from filer",
+		},
+	)
+	scrapeFailureCounter := prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "netapp_disk_scrape_failure_total",
+			Help: "number of failures for fetching disks from filer",
+		},
+	)
+	return &DiskCollector{
+		client:      client,
+		filerName:   filerName,
+		status:      status,
+		diskMetrics: diskMetrics,
+		diskInfoDesc: prometheus.NewDesc(
+			"netapp_disk_info",
+			"Netapp Disk: info about the disk in labels",
+			append(diskLabels, "home_node", "container_type", "disk_type", "model", "firmware_revision", "shelf", "bay"),
+			nil),
+		spareDisksDesc: prometheus.NewDesc(
+			"netapp_node_spare_disks",
+			"Netapp Node: number of spare disks owned by the node",
+			spareLabels,
+			nil),
+		spareZeroedDesc: prometheus.NewDesc(
+			"netapp_node_spare_disks_zeroed",
+			"Netapp Node: number of zeroed spare disks owned by the node",
+			spareLabels,
+			nil),
+		spareUsableDesc: prometheus.NewDesc(
+			"netapp_node_spare_usable_bytes",
+			"Netapp Node: usable size of the spare disks owned by the node",
+			spareLabels,
+			nil),
+		scrapeDurationGauge:  scrapeDurationGauge,
+		scrapeCounter:        scrapeCounter,
+		scrapeFailureCounter: scrapeFailureCounter,
+	}
+}
+
+func (c *DiskCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, m := range c.diskMetrics {
+		ch <- m.desc
+	}
+	ch <- c.diskInfoDesc
+	ch <- c.spareDisksDesc
+	ch <- c.spareZeroedDesc
+	ch <- c.spareUsableDesc
+	ch <- c.scrapeCounter.Desc()
+	ch <- c.scrapeFailureCounter.Desc()
+	ch <- c.scrapeDurationGauge.Desc()
+}
+
+func (c *DiskCollector) Collect(ch chan<- prometheus.Metric) {
+	disks, spares := c.Fetch()
+
+	for _, d := range disks {
+		labels := []string{d.Name, d.Node}
+		for _, m := range c.diskMetrics {
+			ch <- prometheus.MustNewConstMetric(m.desc, m.valueType, m.getterFn(d), labels...)
+		}
+		ch <- prometheus.MustNewConstMetric(c.diskInfoDesc, prometheus.GaugeValue, 1.0,
+			d.Name, d.Node, d.HomeNode, d.ContainerType, d.DiskType, d.Model, d.FirmwareRevision, d.Shelf, d.ShelfBay)
+	}
+	for _, s := range countSpareDisks(disks, spares) {
+		ch <- prometheus.MustNewConstMetric(c.spareDisksDesc, prometheus.GaugeValue, s.count, s.node, s.diskType)
+		ch <- prometheus.MustNewConstMetric(c.spareZeroedDesc, prometheus.GaugeValue, s.zeroed, s.node, s.diskType)
+		ch <- prometheus.MustNewConstMetric(c.spareUsableDesc, prometheus.GaugeValue, s.usableSize, s.node, s.diskType)
+	}
+	c.scrapeCounter.Collect(ch)
+	c.scrapeFailureCounter.Collect(ch)
+	c.scrapeDurationGauge.Collect(ch)
+}
+
+func (c *DiskCollector) Fetch() ([]*netapp.Disk, []*netapp.SpareDisk) {
+	start := time.Now()
+	disks, err := c.client.ListDisks()
+	var spares []*netapp.SpareDisk
+	if err == nil {
+		spares, err = c.client.ListSpareDisks()
+	}
+	elapsed := time.Now().Sub(start)
+	c.scrapeCounter.Inc()
+	c.scrapeDurationGauge.Set(elapsed.Seconds())
+	c.status.reportScrape("disk", err)
+	if err != nil {
+		log.WithField("filer", c.filerName).WithError(err).Error("list disks failed")
+		c.scrapeFailureCounter.Inc()
+		return nil, nil
+	}
+	return disks, spares
+}
+
+// countSpareDisks counts the spares per node and disk type. Every combination
+// of node and disk type found in the disk inventory is reported, so that nodes
+// without spares show up with a count of zero.
+func countSpareDisks(disks []*netapp.Disk, spares []*netapp.SpareDisk) map[string]*spareDiskCount {
+	counts := make(map[string]*spareDiskCount)
+	get := func(node, diskType string) *spareDiskCount {
+		key := node + "/" + diskType
+		if _, ok := counts[key]; !ok {
+			counts[key] = &spareDiskCount{node: node, diskType: diskType}
+		}
+		return counts[key]
+	}
+	for _, d := range disks {
+		if d.Node != "" {
+			get(d.Node, d.DiskType)
+		}
+	}
+	for _, s := range spares {
+		cnt := get(s.Node, s.DiskType)
+		cnt.count++
+		cnt.usableSize += s.UsableSize
+		if s.IsDiskZeroed {
+			cnt.zeroed++
+		}
+	}
+	return counts
+}
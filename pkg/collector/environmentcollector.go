@@ -0,0 +1,135 @@
+package collector
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sapcc/netapp-api-exporter/pkg/netapp"
+	log "github.com/sirupsen/logrus"
+)
+
+var environmentSensorStates = map[string]float64{
+	"normal":      1,
+	"warn_low":    2,
+	"warn_high":   3,
+	"crit_low":    4,
+	"crit_high":   5,
+	"failed":      6,
+	"fault":       7,
+	"bad":         8,
+	"not_present": 9,
+}
+
+type EnvironmentCollector struct {
+	client               *netapp.Client
+	filerName            string
+	status               *FilerStatus
+	valueDesc            *prometheus.Desc
+	stateDesc            *prometheus.Desc
+	thresholdDesc        *prometheus.Desc
+	scrapeCounter        prometheus.Counter
+	scrapeFailureCounter prometheus.Counter
+	scrapeDurationGauge  prometheus.Gauge
+}
+
+func NewEnvironmentCollector(client *netapp.Client, filerName string, status *FilerStatus) *EnvironmentCollector {
+	sensorLabels := []string{"node", "sensor", "sensor_type", "unit"}
+	scrapeDurationGauge := prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "netapp_environment_scrape_duration_seconds",
+			Help: "duration in seconds of fetching environment sensors from filer",
+		},
+	)
+	scrapeCounter := prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "netapp_environment_scrape_total",
+			Help: "number of environment sensor fetches from filer",
+		},
+	)
+	scrapeFailureCounter := prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "netapp_environment_scrape_failure_total",
+			Help: "number of failures for fetching environment sensors from filer",
+		},
+	)
+	return &EnvironmentCollector{
+		client:    client,
+		filerName: filerName,
+		status:    status,
+		valueDesc: prometheus.NewDesc(
+			"netapp_environment_sensor_value",
+			"Netapp Environment Sensor: reading of threshold based sensors in the unit given by label `unit`",
+			sensorLabels,
+			nil),
+		stateDesc: prometheus.NewDesc(
+			"netapp_environment_sensor_state",
+			"Netapp Environment Sensor: state (1: normal; 2: warn_low; 3: warn_high; 4: crit_low; 5: crit_high; "+
+				"6: failed; 7: fault; 8: bad; 9: not_present; 0: other)",
+			sensorLabels,
+			nil),
+		thresholdDesc: prometheus.NewDesc(
+			"netapp_environment_sensor_threshold",
+			"Netapp Environment Sensor: thresholds (warning_low, warning_high, critical_low, critical_high); "+
+				"thresholds which are not set are not exported",
+			append(sensorLabels, "threshold"),
+			nil),
+		scrapeDurationGauge:  scrapeDurationGauge,
+		scrapeCounter:        scrapeCounter,
+		scrapeFailureCounter: scrapeFailureCounter,
+	}
+}
+
+func (c *EnvironmentCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.valueDesc
+	ch <- c.stateDesc
+	ch <- c.thresholdDesc
+	ch <- c.scrapeCounter.Desc()
+	ch <- c.scrapeFailureCounter.Desc()
+	ch <- c.scrapeDurationGauge.Desc()
+}
+
+func (c *EnvironmentCollector) Collect(ch chan<- prometheus.Metric) {
+	sensors := c.Fetch()
+
+	for _, s := range sensors {
+		labels := []string{s.Node, s.Name, s.Type, s.ValueUnits}
+		ch <- prometheus.MustNewConstMetric(c.stateDesc, prometheus.GaugeValue, environmentSensorStates[s.State], labels...)
+		if s.Type == "discrete" {
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(c.valueDesc, prometheus.GaugeValue, s.Value, labels...)
+		thresholds := []struct {
+			name  string
+			value *float64
+		}{
+			{"warning_low", s.WarningLowThreshold},
+			{"warning_high", s.WarningHighThreshold},
+			{"critical_low", s.CriticalLowThreshold},
+			{"critical_high", s.CriticalHighThreshold},
+		}
+		for _, t := range thresholds {
+			if t.value == nil {
+				continue
+			}
+			ch <- prometheus.MustNewConstMetric(c.thresholdDesc, prometheus.GaugeValue, *t.value, append(labels, t.name)...)
+		}
+	}
+	c.scrapeCounter.Collect(ch)
+	c.scrapeFailureCounter.Collect(ch)
+	c.scrapeDurationGauge.Collect(ch)
+}
+
+func (c *EnvironmentCollector) Fetch() []*netapp.EnvironmentSensor {
+	start := time.Now()
+	sensors, err := c.client.ListEnvironmentSensors()
+	elapsed := time.Now().Sub(start)
+	c.scrapeCounter.Inc()
+	c.scrapeDurationGauge.Set(elapsed.Seconds())
+	c.status.reportScrape("environment", err)
+	if err != nil {
+		log.WithField("filer", c.filerName).WithError(err).Error("list environment sensors failed")
+		c.scrapeFailureCounter.Inc()
+		return nil
+	}
+	return sensors
+}
@@ -0,0 +1,145 @@
+package collector
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sapcc/netapp-api-exporter/pkg/netapp"
+	log "github.com/sirupsen/logrus"
+)
+
+type FailoverCollector struct {
+	client               *netapp.Client
+	filerName            string
+	status               *FilerStatus
+	failoverMetrics      []FailoverMetric
+	failoverInfoDesc     *prometheus.Desc
+	scrapeCounter        prometheus.Counter
+	scrapeFailureCounter prometheus.Counter
+	scrapeDurationGauge  prometheus.Gauge
+}
+
+type FailoverMetric struct {
+	desc      *prometheus.Desc
+	valueType prometheus.ValueType
+	getterFn  func(f *netapp.NodeFailover) float64
+}
+
+func NewFailoverCollector(client *netapp.Client, filerName string, status *FilerStatus) *FailoverCollector {
+	failoverLabels := []string{"node", "partner"}
+	failoverMetrics := []FailoverMetric{
+		{
+			desc: prometheus.NewDesc(
+				"netapp_node_takeover_possible",
+				"Netapp Node Failover: node is able to take over its partner",
+				failoverLabels,
+				nil),
+			valueType: prometheus.GaugeValue,
+			getterFn: func(f *netapp.NodeFailover) float64 {
+				if f.TakeoverPossible {
+					return 1.0
+				}
+				return 0.0
+			},
+		}, {
+			desc: prometheus.NewDesc(
+				"netapp_node_takeover_enabled",
+				"Netapp Node Failover: storage failover is enabled",
+				failoverLabels,
+				nil),
+			valueType: prometheus.GaugeValue,
+			getterFn: func(f *netapp.NodeFailover) float64 {
+				if f.TakeoverEnabled {
+					return 1.0
+				}
+				return 0.0
+			},
+		}, {
+			desc: prometheus.NewDesc(
+				"netapp_node_interconnect_up",
+				"Netapp Node Failover: ha interconnect to the partner is up",
+				failoverLabels,
+				nil),
+			valueType: prometheus.GaugeValue,
+			getterFn: func(f *netapp.NodeFailover) float64 {
+				if f.InterconnectUp {
+					return 1.0
+				}
+				return 0.0
+			},
+		},
+	}
+	scrapeDurationGauge := prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "netapp_failover_scrape_duration_seconds",
+			Help: "duration in seconds of fetching storage failover info from filer",
+		},
+	)
+	scrapeCounter := prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "netapp_failover_scrape_total",
+			Help: "number of storage failover info fetches from filer",
+		},
+	)
+	scrapeFailureCounter := prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "netapp_failover_scrape_failure_total",
+			Help: "number of failures for fetching storage failover info from filer",
+		},
+	)
+	return &FailoverCollector{
+		client:          client,
+		filerName:       filerName,
+		status:          status,
+		failoverMetrics: failoverMetrics,
+		failoverInfoDesc: prometheus.NewDesc(
+			"netapp_node_failover_info",
+			"Netapp Node Failover: current failover state in labels",
+			append(failoverLabels, "current_mode", "node_state", "takeover_state", "giveback_state", "interconnect_type"),
+			nil),
+		scrapeDurationGauge:  scrapeDurationGauge,
+		scrapeCounter:        scrapeCounter,
+		scrapeFailureCounter: scrapeFailureCounter,
+	}
+}
+
+func (c *FailoverCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, m := range c.failoverMetrics {
+		ch <- m.desc
+	}
+	ch <- c.failoverInfoDesc
+	ch <- c.scrapeCounter.Desc()
+	ch <- c.scrapeFailureCounter.Desc()
+	ch <- c.scrapeDurationGauge.Desc()
+}
+
+func (c *FailoverCollector) Collect(ch chan<- prometheus.Metric) {
+	failovers := c.Fetch()
+
+	for _, f := range failovers {
+		labels := []string{f.Node, f.Partner}
+		for _, m := range c.failoverMetrics {
+			ch <- prometheus.MustNewConstMetric(m.desc, m.valueType, m.getterFn(f), labels...)
+		}
+		ch <- prometheus.MustNewConstMetric(c.failoverInfoDesc, prometheus.GaugeValue, 1.0,
+			f.Node, f.Partner, f.CurrentMode, f.NodeState, f.TakeoverState, f.GivebackState, f.InterconnectType)
+	}
+	c.scrapeCounter.Collect(ch)
+	c.scrapeFailureCounter.Collect(ch)
+	c.scrapeDurationGauge.Collect(ch)
+}
+
+func (c *FailoverCollector) Fetch() []*netapp.NodeFailover {
+	start := time.Now()
+	failovers, err := c.client.ListNodeFailovers()
+	elapsed := time.Now().Sub(start)
+	c.scrapeCounter.Inc()
+	c.scrapeDurationGauge.Set(elapsed.Seconds())
+	c.status.reportScrape("failover", err)
+	if err != nil {
+		log.WithField("filer", c.filerName).WithError(err).Error("list storage failover info failed")
+		c.scrapeFailureCounter.Inc()
+		return nil
+	}
+	return failovers
+}
@@ -0,0 +1,127 @@
+package collector
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sapcc/netapp-api-exporter/pkg/netapp"
+	log "github.com/sirupsen/logrus"
+)
+
+type FcpCollector struct {
+	client               *netapp.Client
+	filerName            string
+	status               *FilerStatus
+	adapterMetrics       []FcpAdapterMetric
+	adapterInfoDesc      *prometheus.Desc
+	scrapeCounter        prometheus.Counter
+	scrapeFailureCounter prometheus.Counter
+	scrapeDurationGauge  prometheus.Gauge
+}
+
+type FcpAdapterMetric struct {
+	desc      *prometheus.Desc
+	valueType prometheus.ValueType
+	getterFn  func(a *netapp.FcpAdapter) float64
+}
+
+func NewFcpCollector(client *netapp.Client, filerName string, status *FilerStatus) *FcpCollector {
+	adapterLabels := []string{"node", "adapter"}
+	adapterMetrics := []FcpAdapterMetric{
+		{
+			desc:      prometheus.NewDesc("netapp_fcp_adapter_online", "Netapp FCP Adapter: state is online", adapterLabels, nil),
+			valueType: prometheus.GaugeValue,
+			getterFn: func(a *netapp.FcpAdapter) float64 {
+				if a.State == "online" {
+					return 1.0
+				}
+				return 0.0
+			},
+		}, {
+			desc:      prometheus.NewDesc("netapp_fcp_adapter_configured_speed_bytes_per_second", "Netapp FCP Adapter: configured speed in bytes per second; 0 if auto", adapterLabels, nil),
+			valueType: prometheus.GaugeValue,
+			getterFn:  func(a *netapp.FcpAdapter) float64 { return a.ConfiguredSpeed * 1000 * 1000 * 1000 / 8 },
+		}, {
+			desc:      prometheus.NewDesc("netapp_fcp_adapter_negotiated_speed_bytes_per_second", "Netapp FCP Adapter: negotiated data link rate in bytes per second", adapterLabels, nil),
+			valueType: prometheus.GaugeValue,
+			getterFn:  func(a *netapp.FcpAdapter) float64 { return a.NegotiatedSpeed * 1000 * 1000 * 1000 / 8 },
+		}, {
+			desc:      prometheus.NewDesc("netapp_fcp_adapter_max_speed_bytes_per_second", "Netapp FCP Adapter: maximum speed in bytes per second", adapterLabels, nil),
+			valueType: prometheus.GaugeValue,
+			getterFn:  func(a *netapp.FcpAdapter) float64 { return a.MaxSpeed * 1000 * 1000 * 1000 / 8 },
+		},
+	}
+	scrapeDurationGauge := prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "netapp_fcp_scrape_duration_seconds",
+			Help: "duration in seconds of fetching fc adapters from filer",
+		},
+	)
+	scrapeCounter := prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "netapp_fcp_scrape_total",
+			Help: "number of fc adapter fetches from filer",
+		},
+	)
+	scrapeFailureCounter := prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "netapp_fcp_scrape_failure_total",
+			Help: "number of failures for fetching fc adapters from filer",
+		},
+	)
+	return &FcpCollector{
+		client:         client,
+		filerName:      filerName,
+		status:         status,
+		adapterMetrics: adapterMetrics,
+		adapterInfoDesc: prometheus.NewDesc(
+			"netapp_fcp_adapter_info",
+			"Netapp FCP Adapter: info about the adapter and its link state in labels",
+			append(adapterLabels, "wwpn", "state", "media_type", "switch_port", "physical_link_state", "link_state"),
+			nil),
+		scrapeDurationGauge:  scrapeDurationGauge,
+		scrapeCounter:        scrapeCounter,
+		scrapeFailureCounter: scrapeFailureCounter,
+	}
+}
+
+func (c *FcpCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, m := range c.adapterMetrics {
+		ch <- m.desc
+	}
+	ch <- c.adapterInfoDesc
+	ch <- c.scrapeCounter.Desc()
+	ch <- c.scrapeFailureCounter.Desc()
+	ch <- c.scrapeDurationGauge.Desc()
+}
+
+func (c *FcpCollector) Collect(ch chan<- prometheus.Metric) {
+	adapters := c.Fetch()
+
+	for _, a := range adapters {
+		labels := []string{a.Node, a.Adapter}
+		for _, m := range c.adapterMetrics {
+			ch <- prometheus.MustNewConstMetric(m.desc, m.valueType, m.getterFn(a), labels...)
+		}
+		ch <- prometheus.MustNewConstMetric(c.adapterInfoDesc, prometheus.GaugeValue, 1.0,
+			a.Node, a.Adapter, a.WWPN, a.State, a.MediaType, a.SwitchPort, a.PhysicalLinkState, a.LinkState)
+	}
+	c.scrapeCounter.Collect(ch)
+	c.scrapeFailureCounter.Collect(ch)
+	c.scrapeDurationGauge.Collect(ch)
+}
+
+func (c *FcpCollector) Fetch() []*netapp.FcpAdapter {
+	start := time.Now()
+	adapters, err := c.client.ListFcpAdapters()
+	elapsed := time.Now().Sub(start)
+	c.scrapeCounter.Inc()
+	c.scrapeDurationGauge.Set(elapsed.Seconds())
+	c.status.reportScrape("fcp", err)
+	if err != nil {
+		log.WithField("filer", c.filerName).WithError(err).Error("list fc adapters failed")
+		c.scrapeFailureCounter.Inc()
+		return nil
+	}
+	return adapters
+}
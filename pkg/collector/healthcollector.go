@@ -0,0 +1,158 @@
+package collector
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sapcc/netapp-api-exporter/pkg/netapp"
+	log "github.com/sirupsen/logrus"
+)
+
+type HealthCollector struct {
+	client               *netapp.Client
+	filerName            string
+	status               *FilerStatus
+	alertMetrics         []HealthAlertMetric
+	raisedAlertsCounter  *prometheus.CounterVec
+	knownAlerts          map[string]bool
+	scrapeCounter        prometheus.Counter
+	scrapeFailureCounter prometheus.Counter
+	scrapeDurationGauge  prometheus.Gauge
+	mux                  sync.Mutex
+}
+
+type HealthAlertMetric struct {
+	desc      *prometheus.Desc
+	valueType prometheus.ValueType
+	getterFn  func(a *netapp.HealthAlert) float64
+}
+
+func NewHealthCollector(client *netapp.Client, filerName string, status *FilerStatus) *HealthCollector {
+	alertLabels := []string{"node", "monitor", "subsystem", "alert_id", "alerting_resource", "severity", "probable_cause"}
+	alertMetrics := []HealthAlertMetric{
+		{
+			desc:      prometheus.NewDesc("netapp_health_alert", "Netapp Health: alert raised by the system health monitor", alertLabels, nil),
+			valueType: prometheus.GaugeValue,
+			getterFn:  func(a *netapp.HealthAlert) float64 { return 1.0 },
+		}, {
+			desc:      prometheus.NewDesc("netapp_health_alert_acknowledged", "Netapp Health: alert is acknowledged", alertLabels, nil),
+			valueType: prometheus.GaugeValue,
+			getterFn: func(a *netapp.HealthAlert) float64 {
+				if a.Acknowledged {
+					return 1.0
+				}
+				return 0.0
+			},
+		}, {
+			desc:      prometheus.NewDesc("netapp_health_alert_suppressed", "Netapp Health: alert is suppressed", alertLabels, nil),
+			valueType: prometheus.GaugeValue,
+			getterFn: func(a *netapp.HealthAlert) float64 {
+				if a.Suppressed {
+					return 1.0
+				}
+				return 0.0
+			},
+		}, {
+			desc:      prometheus.NewDesc("netapp_health_alert_indication_timestamp_seconds", "Netapp Health: time when the alert was raised", alertLabels, nil),
+			valueType: prometheus.GaugeValue,
+			getterFn:  func(a *netapp.HealthAlert) float64 { return a.IndicationTime },
+		},
+	}
+	raisedAlertsCounter := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "netapp_health_alerts_raised_total",
+			Help: "number of health alerts newly raised since the exporter started",
+		},
+		[]string{"node", "subsystem", "severity"},
+	)
+	scrapeDurationGauge := prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "netapp_health_scrape_duration_seconds",
+			Help: "duration in seconds of fetching health alerts from filer",
+		},
+	)
+	scrapeCounter := prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "netapp_health_scrape_total",
+			Help: "number of health alert fetches from filer",
+		},
+	)
+	scrapeFailureCounter := prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "netapp_health_scrape_failure_total",
+			Help: "number of failures for fetching health alerts from filer",
+		},
+	)
+	return &HealthCollector{
+		client:               client,
+		filerName:            filerName,
+		status:               status,
+		alertMetrics:         alertMetrics,
+		raisedAlertsCounter:  raisedAlertsCounter,
+		scrapeDurationGauge:  scrapeDurationGauge,
+		scrapeCounter:        scrapeCounter,
+		scrapeFailureCounter: scrapeFailureCounter,
+	}
+}
+
+func (c *HealthCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, m := range c.alertMetrics {
+		ch <- m.desc
+	}
+	c.raisedAlertsCounter.Describe(ch)
+	ch <- c.scrapeCounter.Desc()
+	ch <- c.scrapeFailureCounter.Desc()
+	ch <- c.scrapeDurationGauge.Desc()
+}
+
+func (c *HealthCollector) Collect(ch chan<- prometheus.Metric) {
+	alerts := c.Fetch()
+
+	for _, a := range alerts {
+		labels := []string{a.Node, a.Monitor, a.Subsystem, a.ID, a.AlertingResource, a.PerceivedSeverity, a.ProbableCause}
+		for _, m := range c.alertMetrics {
+			ch <- prometheus.MustNewConstMetric(m.desc, m.valueType, m.getterFn(a), labels...)
+		}
+	}
+	c.raisedAlertsCounter.Collect(ch)
+	c.scrapeCounter.Collect(ch)
+	c.scrapeFailureCounter.Collect(ch)
+	c.scrapeDurationGauge.Collect(ch)
+}
+
+func (c *HealthCollector) Fetch() []*netapp.HealthAlert {
+	start := time.Now()
+	alerts, err := c.client.ListHealthAlerts()
+	elapsed := time.Now().Sub(start)
+	c.scrapeCounter.Inc()
+	c.scrapeDurationGauge.Set(elapsed.Seconds())
+	c.status.reportScrape("health", err)
+	if err != nil {
+		log.WithField("filer", c.filerName).WithError(err).Error("list health alerts failed")
+		c.scrapeFailureCounter.Inc()
+		return nil
+	}
+	c.countRaisedAlerts(alerts)
+	return alerts
+}
+
+// countRaisedAlerts compares the alerts with those of the previous fetch and
+// increments the counter for new ones. Alerts present at the first fetch are
+// not counted, since it is unknown when they have been raised.
+func (c *HealthCollector) countRaisedAlerts(alerts []*netapp.HealthAlert) {
+	defer c.mux.Unlock()
+	c.mux.Lock()
+
+	known := make(map[string]bool, len(alerts))
+	for _, a := range alerts {
+		// an alert which is raised again has a new indication time
+		key := fmt.Sprintf("%s/%s/%s/%.0f", a.Node, a.ID, a.AlertingResource, a.IndicationTime)
+		known[key] = true
+		if c.knownAlerts != nil && !c.knownAlerts[key] {
+			c.raisedAlertsCounter.WithLabelValues(a.Node, a.Subsystem, a.PerceivedSeverity).Inc()
+		}
+	}
+	c.knownAlerts = known
+}
@@ -0,0 +1,189 @@
+package collector
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sapcc/netapp-api-exporter/pkg/netapp"
+	log "github.com/sirupsen/logrus"
+)
+
+// job-event-type values of finished jobs
+var (
+	jobCompletedEventTypes = map[string]bool{"success": true}
+	jobFailedEventTypes    = map[string]bool{"failure": true, "error": true, "dead": true}
+)
+
+type JobCollector struct {
+	client               *netapp.Client
+	filerName            string
+	status               *FilerStatus
+	completedJobsDesc    *prometheus.Desc
+	failedJobsDesc       *prometheus.Desc
+	runningJobsDesc      *prometheus.Desc
+	oldestRunningJobDesc *prometheus.Desc
+	// finished jobs per node and job name, counted since the first fetch
+	completed            map[[2]string]float64
+	failed               map[[2]string]float64
+	seeded               bool
+	lastEventTime        int64
+	lastEventLogIDs      map[int]bool
+	scrapeCounter        prometheus.Counter
+	scrapeFailureCounter prometheus.Counter
+	scrapeDurationGauge  prometheus.Gauge
+	mux                  sync.Mutex
+}
+
+func NewJobCollector(client *netapp.Client, filerName string, status *FilerStatus) *JobCollector {
+	jobLabels := []string{"node", "job"}
+	scrapeDurationGauge := prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "netapp_job_scrape_duration_seconds",
+			Help: "duration in seconds of fetching jobs from filer",
+		},
+	)
+	scrapeCounter := prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "netapp_job_scrape_total",
+			Help: "number of job fetches from filer",
+		},
+	)
+	scrapeFailureCounter := prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "netapp_job_scrape_failure_total",
+			Help: "number of failures for fetching jobs from filer",
+		},
+	)
+	return &JobCollector{
+		client:               client,
+		filerName:            filerName,
+		status:               status,
+		completedJobsDesc:    prometheus.NewDesc("netapp_jobs_completed_total", "Netapp Job: number of jobs completed successfully since the exporter started", jobLabels, nil),
+		failedJobsDesc:       prometheus.NewDesc("netapp_jobs_failed_total", "Netapp Job: number of jobs failed since the exporter started", jobLabels, nil),
+		runningJobsDesc:      prometheus.NewDesc("netapp_jobs_running", "Netapp Job: number of currently running jobs", jobLabels, nil),
+		oldestRunningJobDesc: prometheus.NewDesc("netapp_job_oldest_running_age_seconds", "Netapp Job: age of the oldest currently running job", jobLabels, nil),
+		completed:            make(map[[2]string]float64),
+		failed:               make(map[[2]string]float64),
+		scrapeDurationGauge:  scrapeDurationGauge,
+		scrapeCounter:        scrapeCounter,
+		scrapeFailureCounter: scrapeFailureCounter,
+	}
+}
+
+func (c *JobCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.completedJobsDesc
+	ch <- c.failedJobsDesc
+	ch <- c.runningJobsDesc
+	ch <- c.oldestRunningJobDesc
+	ch <- c.scrapeCounter.Desc()
+	ch <- c.scrapeFailureCounter.Desc()
+	ch <- c.scrapeDurationGauge.Desc()
+}
+
+func (c *JobCollector) Collect(ch chan<- prometheus.Metric) {
+	runningJobs := c.Fetch()
+
+	c.mux.Lock()
+	for k, v := range c.completed {
+		ch <- prometheus.MustNewConstMetric(c.completedJobsDesc, prometheus.CounterValue, v, k[0], k[1])
+	}
+	for k, v := range c.failed {
+		ch <- prometheus.MustNewConstMetric(c.failedJobsDesc, prometheus.CounterValue, v, k[0], k[1])
+	}
+	c.mux.Unlock()
+
+	now := float64(time.Now().Unix())
+	running := make(map[[2]string]float64)
+	oldestStart := make(map[[2]string]float64)
+	for _, j := range runningJobs {
+		k := [2]string{j.Node, j.Name}
+		running[k]++
+		if start, ok := oldestStart[k]; !ok || j.StartTime < start {
+			oldestStart[k] = j.StartTime
+		}
+	}
+	for k, v := range running {
+		ch <- prometheus.MustNewConstMetric(c.runningJobsDesc, prometheus.GaugeValue, v, k[0], k[1])
+		age := now - oldestStart[k]
+		if age < 0 {
+			age = 0
+		}
+		ch <- prometheus.MustNewConstMetric(c.oldestRunningJobDesc, prometheus.GaugeValue, age, k[0], k[1])
+	}
+
+	c.scrapeCounter.Collect(ch)
+	c.scrapeFailureCounter.Collect(ch)
+	c.scrapeDurationGauge.Collect(ch)
+}
+
+// Fetch counts the jobs finished since the previous fetch and returns the
+// running jobs.
+func (c *JobCollector) Fetch() []*netapp.RunningJob {
+	defer c.mux.Unlock()
+	c.mux.Lock()
+
+	start := time.Now()
+	// The history window starts at the latest event seen in the previous
+	// fetch. The first fetch reads the whole history, which only seeds the
+	// window with the filer's latest event, so that the window does not depend
+	// on the clock of the exporter.
+	events, err := c.client.ListJobEvents(c.lastEventTime)
+	var runningJobs []*netapp.RunningJob
+	if err == nil {
+		runningJobs, err = c.client.ListRunningJobs()
+	}
+	elapsed := time.Now().Sub(start)
+	c.scrapeCounter.Inc()
+	c.scrapeDurationGauge.Set(elapsed.Seconds())
+	c.status.reportScrape("job", err)
+	if err != nil {
+		log.WithField("filer", c.filerName).WithError(err).Error("list jobs failed")
+		c.scrapeFailureCounter.Inc()
+		return nil
+	}
+	c.countJobEvents(events, c.seeded)
+	c.seeded = true
+	return runningJobs
+}
+
+// countJobEvents counts the finished jobs per node and job name and moves the
+// history window forward. Events of the first second of the window have
+// possibly been counted in the previous fetch already, so they are
+// deduplicated by their log id. Without count, the jobs of the events are only
+// initialized with 0.
+func (c *JobCollector) countJobEvents(events []*netapp.JobEvent, count bool) {
+	lastEventTime := c.lastEventTime
+	for _, e := range events {
+		if e.EventTime > lastEventTime {
+			lastEventTime = e.EventTime
+		}
+	}
+	lastEventLogIDs := make(map[int]bool)
+	if lastEventTime == c.lastEventTime {
+		// keep the log ids of the boundary second, if no later event was seen
+		for id := range c.lastEventLogIDs {
+			lastEventLogIDs[id] = true
+		}
+	}
+	for _, e := range events {
+		if e.EventTime == lastEventTime {
+			lastEventLogIDs[e.LogID] = true
+		}
+		if e.EventTime == c.lastEventTime && c.lastEventLogIDs[e.LogID] {
+			continue
+		}
+		k := [2]string{e.Node, e.Name}
+		var n float64
+		if count {
+			n = 1
+		}
+		if jobCompletedEventTypes[e.EventType] {
+			c.completed[k] += n
+		} else if jobFailedEventTypes[e.EventType] {
+			c.failed[k] += n
+		}
+	}
+	c.lastEventTime = lastEventTime
+	c.lastEventLogIDs = lastEventLogIDs
+}
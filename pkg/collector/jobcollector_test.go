@@ -0,0 +1,72 @@
+package collector
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/sapcc/netapp-api-exporter/pkg/netapp"
+)
+
+func TestCountJobEvents(t *testing.T) {
+	c := NewJobCollector(nil, "filer", nil)
+	event := func(logID int, node, name, eventType string, eventTime int64) *netapp.JobEvent {
+		return &netapp.JobEvent{LogID: logID, Node: node, Name: name, EventType: eventType, EventTime: eventTime}
+	}
+	snapshot := [2]string{"node1", "snapshot"}
+	mirror := [2]string{"node2", "mirror"}
+
+	// the first fetch reads the whole history and only seeds the counters
+	c.countJobEvents([]*netapp.JobEvent{
+		event(1, "node1", "snapshot", "success", 90),
+		event(2, "node1", "snapshot", "success", 100),
+		event(3, "node1", "snapshot", "failure", 100),
+	}, false)
+	checkJobCounts(t, "seed", c, map[[2]string]float64{snapshot: 0}, map[[2]string]float64{snapshot: 0})
+	if c.lastEventTime != 100 {
+		t.Errorf("seed: lastEventTime = %d, want 100", c.lastEventTime)
+	}
+
+	// the events of the boundary second are returned again, only new log ids
+	// are counted; event types which do not finish a job are ignored
+	c.countJobEvents([]*netapp.JobEvent{
+		event(2, "node1", "snapshot", "success", 100),
+		event(3, "node1", "snapshot", "failure", 100),
+		event(4, "node1", "snapshot", "success", 100),
+		event(5, "node1", "snapshot", "error", 105),
+		event(6, "node2", "mirror", "dead", 105),
+		event(7, "node2", "mirror", "running", 105),
+	}, true)
+	checkJobCounts(t, "second fetch", c,
+		map[[2]string]float64{snapshot: 1},
+		map[[2]string]float64{snapshot: 1, mirror: 1})
+
+	c.countJobEvents([]*netapp.JobEvent{
+		event(5, "node1", "snapshot", "error", 105),
+		event(6, "node2", "mirror", "dead", 105),
+		event(8, "node2", "mirror", "success", 105),
+	}, true)
+	checkJobCounts(t, "third fetch", c,
+		map[[2]string]float64{snapshot: 1, mirror: 1},
+		map[[2]string]float64{snapshot: 1, mirror: 1})
+
+	// a fetch without events keeps the window, so that the events of the
+	// boundary second are not counted again later
+	c.countJobEvents(nil, true)
+	c.countJobEvents([]*netapp.JobEvent{
+		event(5, "node1", "snapshot", "error", 105),
+		event(8, "node2", "mirror", "success", 105),
+	}, true)
+	checkJobCounts(t, "after empty fetch", c,
+		map[[2]string]float64{snapshot: 1, mirror: 1},
+		map[[2]string]float64{snapshot: 1, mirror: 1})
+}
+
+func checkJobCounts(t *testing.T, name string, c *JobCollector, completed, failed map[[2]string]float64) {
+	t.Helper()
+	if !reflect.DeepEqual(c.completed, completed) {
+		t.Errorf("%s: completed = %v, want %v", name, c.completed, completed)
+	}
+	if !reflect.DeepEqual(c.failed, failed) {
+		t.Errorf("%s: failed = %v, want %v", name, c.failed, failed)
+	}
+}
@@ -0,0 +1,143 @@
+package collector
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sapcc/netapp-api-exporter/pkg/netapp"
+	log "github.com/sirupsen/logrus"
+)
+
+type LunCollector struct {
+	client               *netapp.Client
+	filerName            string
+	status               *FilerStatus
+	lunMetrics           []LunMetric
+	thinProvisionedDesc  *prometheus.Desc
+	scrapeCounter        prometheus.Counter
+	scrapeFailureCounter prometheus.Counter
+	scrapeDurationGauge  prometheus.Gauge
+}
+
+type LunMetric struct {
+	desc      *prometheus.Desc
+	valueType prometheus.ValueType
+	getterFn  func(l *netapp.Lun) float64
+}
+
+func NewLunCollector(client *netapp.Client, filerName string, status *FilerStatus) *LunCollector {
+	lunLabels := []string{"vserver", "volume", "qtree", "lun", "node"}
+	lunMetrics := []LunMetric{
+		{
+			desc:      prometheus.NewDesc("netapp_lun_size_bytes", "Netapp Lun: size", lunLabels, nil),
+			valueType: prometheus.GaugeValue,
+			getterFn:  func(l *netapp.Lun) float64 { return l.Size },
+		}, {
+			desc:      prometheus.NewDesc("netapp_lun_used_bytes", "Netapp Lun: size used", lunLabels, nil),
+			valueType: prometheus.GaugeValue,
+			getterFn:  func(l *netapp.Lun) float64 { return l.SizeUsed },
+		}, {
+			desc:      prometheus.NewDesc("netapp_lun_online", "Netapp Lun: is online", lunLabels, nil),
+			valueType: prometheus.GaugeValue,
+			getterFn: func(l *netapp.Lun) float64 {
+				if l.Online {
+					return 1.0
+				}
+				return 0.0
+			},
+		}, {
+			desc:      prometheus.NewDesc("netapp_lun_mapped", "Netapp Lun: is mapped to an initiator group", lunLabels, nil),
+			valueType: prometheus.GaugeValue,
+			getterFn: func(l *netapp.Lun) float64 {
+				if l.Mapped {
+					return 1.0
+				}
+				return 0.0
+			},
+		}, {
+			desc:      prometheus.NewDesc("netapp_lun_space_reservation_enabled", "Netapp Lun: space reservation is enabled", lunLabels, nil),
+			valueType: prometheus.GaugeValue,
+			getterFn: func(l *netapp.Lun) float64 {
+				if l.IsSpaceReservationEnabled {
+					return 1.0
+				}
+				return 0.0
+			},
+		},
+	}
+	scrapeDurationGauge := prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "netapp_lun_scrape_duration_seconds",
+			Help: "duration in seconds of fetching luns from filer",
+		},
+	)
+	scrapeCounter := prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "netapp_lun_scrape_total",
+			Help: "number of lun fetches from filer",
+		},
+	)
+	scrapeFailureCounter := prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "netapp_lun_scrape_failure_total",
+			Help: "number of failures for fetching luns from filer",
+		},
+	)
+	return &LunCollector{
+		client:               client,
+		filerName:            filerName,
+		status:               status,
+		lunMetrics:           lunMetrics,
+		thinProvisionedDesc:  prometheus.NewDesc("netapp_lun_thin_provisioned", "Netapp Lun: is thin provisioned", lunLabels, nil),
+		scrapeDurationGauge:  scrapeDurationGauge,
+		scrapeCounter:        scrapeCounter,
+		scrapeFailureCounter: scrapeFailureCounter,
+	}
+}
+
+func (c *LunCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, m := range c.lunMetrics {
+		ch <- m.desc
+	}
+	ch <- c.thinProvisionedDesc
+	ch <- c.scrapeCounter.Desc()
+	ch <- c.scrapeFailureCounter.Desc()
+	ch <- c.scrapeDurationGauge.Desc()
+}
+
+func (c *LunCollector) Collect(ch chan<- prometheus.Metric) {
+	luns := c.Fetch()
+
+	for _, l := range luns {
+		labels := []string{l.Vserver, l.Volume, l.Qtree, l.Path, l.Node}
+		for _, m := range c.lunMetrics {
+			ch <- prometheus.MustNewConstMetric(m.desc, m.valueType, m.getterFn(l), labels...)
+		}
+		// not exported if the filer does not report it
+		if l.IsThinProvisioned != nil {
+			var thin float64
+			if *l.IsThinProvisioned {
+				thin = 1.0
+			}
+			ch <- prometheus.MustNewConstMetric(c.thinProvisionedDesc, prometheus.GaugeValue, thin, labels...)
+		}
+	}
+	c.scrapeCounter.Collect(ch)
+	c.scrapeFailureCounter.Collect(ch)
+	c.scrapeDurationGauge.Collect(ch)
+}
+
+func (c *LunCollector) Fetch() []*netapp.Lun {
+	start := time.Now()
+	luns, err := c.client.ListLuns()
+	elapsed := time.Now().Sub(start)
+	c.scrapeCounter.Inc()
+	c.scrapeDurationGauge.Set(elapsed.Seconds())
+	c.status.reportScrape("lun", err)
+	if err != nil {
+		log.WithField("filer", c.filerName).WithError(err).Error("list luns failed")
+		c.scrapeFailureCounter.Inc()
+		return nil
+	}
+	return luns
+}
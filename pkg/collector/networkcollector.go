@@ -0,0 +1,211 @@
+package collector
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sapcc/netapp-api-exporter/pkg/netapp"
+	log "github.com/sirupsen/logrus"
+)
+
+type NetworkCollector struct {
+	client               *netapp.Client
+	filerName            string
+	status               *FilerStatus
+	portMetrics          []NetPortMetric
+	portInfoDesc         *prometheus.Desc
+	interfaceMetrics     []NetInterfaceMetric
+	interfaceInfoDesc    *prometheus.Desc
+	scrapeCounter        prometheus.Counter
+	scrapeFailureCounter prometheus.Counter
+	scrapeDurationGauge  prometheus.Gauge
+}
+
+type NetPortMetric struct {
+	desc      *prometheus.Desc
+	valueType prometheus.ValueType
+	getterFn  func(p *netapp.NetPort) float64
+}
+
+type NetInterfaceMetric struct {
+	desc      *prometheus.Desc
+	valueType prometheus.ValueType
+	getterFn  func(l *netapp.NetInterface) float64
+}
+
+func NewNetworkCollector(client *netapp.Client, filerName string, status *FilerStatus) *NetworkCollector {
+	portLabels := []string{"node", "port"}
+	portMetrics := []NetPortMetric{
+		{
+			desc:      prometheus.NewDesc("netapp_net_port_link_up", "Netapp Net Port: link status is up", portLabels, nil),
+			valueType: prometheus.GaugeValue,
+			getterFn: func(p *netapp.NetPort) float64 {
+				if p.LinkStatus == "up" {
+					return 1.0
+				}
+				return 0.0
+			},
+		}, {
+			desc:      prometheus.NewDesc("netapp_net_port_admin_up", "Netapp Net Port: port is administratively up", portLabels, nil),
+			valueType: prometheus.GaugeValue,
+			getterFn: func(p *netapp.NetPort) float64 {
+				if p.IsAdminUp {
+					return 1.0
+				}
+				return 0.0
+			},
+		}, {
+			desc:      prometheus.NewDesc("netapp_net_port_healthy", "Netapp Net Port: health status is healthy", portLabels, nil),
+			valueType: prometheus.GaugeValue,
+			getterFn: func(p *netapp.NetPort) float64 {
+				if p.HealthStatus == "healthy" {
+					return 1.0
+				}
+				return 0.0
+			},
+		}, {
+			desc:      prometheus.NewDesc("netapp_net_port_speed_bytes_per_second", "Netapp Net Port: operational speed in bytes per second; 0 if unknown", portLabels, nil),
+			valueType: prometheus.GaugeValue,
+			getterFn:  func(p *netapp.NetPort) float64 { return p.Speed * 1000 * 1000 / 8 },
+		}, {
+			desc:      prometheus.NewDesc("netapp_net_port_mtu_bytes", "Netapp Net Port: mtu", portLabels, nil),
+			valueType: prometheus.GaugeValue,
+			getterFn:  func(p *netapp.NetPort) float64 { return p.Mtu },
+		},
+	}
+	interfaceLabels := []string{"vserver", "interface"}
+	interfaceMetrics := []NetInterfaceMetric{
+		{
+			desc:      prometheus.NewDesc("netapp_net_interface_up", "Netapp Net Interface: operational status is up", interfaceLabels, nil),
+			valueType: prometheus.GaugeValue,
+			getterFn: func(l *netapp.NetInterface) float64 {
+				if l.OperationalUp {
+					return 1.0
+				}
+				return 0.0
+			},
+		}, {
+			desc:      prometheus.NewDesc("netapp_net_interface_admin_up", "Netapp Net Interface: administrative status is up", interfaceLabels, nil),
+			valueType: prometheus.GaugeValue,
+			getterFn: func(l *netapp.NetInterface) float64 {
+				if l.AdministrativeUp {
+					return 1.0
+				}
+				return 0.0
+			},
+		}, {
+			desc:      prometheus.NewDesc("netapp_net_interface_is_home", "Netapp Net Interface: interface is on its home node and port", interfaceLabels, nil),
+			valueType: prometheus.GaugeValue,
+			getterFn: func(l *netapp.NetInterface) float64 {
+				if l.IsHome {
+					return 1.0
+				}
+				return 0.0
+			},
+		}, {
+			desc:      prometheus.NewDesc("netapp_net_interface_auto_revert", "Netapp Net Interface: interface reverts automatically to its home port", interfaceLabels, nil),
+			valueType: prometheus.GaugeValue,
+			getterFn: func(l *netapp.NetInterface) float64 {
+				if l.IsAutoRevert {
+					return 1.0
+				}
+				return 0.0
+			},
+		},
+	}
+	scrapeDurationGauge := prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "netapp_net_scrape_duration_seconds",
+			Help: "duration in seconds of fetching network ports and interfaces from filer",
+		},
+	)
+	scrapeCounter := prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "netapp_net_scrape_total",
+			Help: "number of network port and interface fetches from filer",
+		},
+	)
+	scrapeFailureCounter := prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "netapp_net_scrape_failure_total",
+			Help: "number of failures for fetching network ports and interfaces from filer",
+		},
+	)
+	return &NetworkCollector{
+		client:      client,
+		filerName:   filerName,
+		status:      status,
+		portMetrics: portMetrics,
+		portInfoDesc: prometheus.NewDesc(
+			"netapp_net_port_info",
+			"Netapp Net Port: info about the port in labels",
+			append(portLabels, "port_type", "role", "broadcast_domain", "ipspace", "health_status"),
+			nil),
+		interfaceMetrics: interfaceMetrics,
+		interfaceInfoDesc: prometheus.NewDesc(
+			"netapp_net_interface_info",
+			"Netapp Net Interface: info about the interface in labels",
+			append(interfaceLabels, "role", "address", "current_node", "current_port", "home_node", "home_port"),
+			nil),
+		scrapeDurationGauge:  scrapeDurationGauge,
+		scrapeCounter:        scrapeCounter,
+		scrapeFailureCounter: scrapeFailureCounter,
+	}
+}
+
+func (c *NetworkCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, m := range c.portMetrics {
+		ch <- m.desc
+	}
+	ch <- c.portInfoDesc
+	for _, m := range c.interfaceMetrics {
+		ch <- m.desc
+	}
+	ch <- c.interfaceInfoDesc
+	ch <- c.scrapeCounter.Desc()
+	ch <- c.scrapeFailureCounter.Desc()
+	ch <- c.scrapeDurationGauge.Desc()
+}
+
+func (c *NetworkCollector) Collect(ch chan<- prometheus.Metric) {
+	ports, lifs := c.Fetch()
+
+	for _, p := range ports {
+		labels := []string{p.Node, p.Port}
+		for _, m := range c.portMetrics {
+			ch <- prometheus.MustNewConstMetric(m.desc, m.valueType, m.getterFn(p), labels...)
+		}
+		ch <- prometheus.MustNewConstMetric(c.portInfoDesc, prometheus.GaugeValue, 1.0,
+			p.Node, p.Port, p.PortType, p.Role, p.BroadcastDomain, p.IPSpace, p.HealthStatus)
+	}
+	for _, l := range lifs {
+		labels := []string{l.Vserver, l.Name}
+		for _, m := range c.interfaceMetrics {
+			ch <- prometheus.MustNewConstMetric(m.desc, m.valueType, m.getterFn(l), labels...)
+		}
+		ch <- prometheus.MustNewConstMetric(c.interfaceInfoDesc, prometheus.GaugeValue, 1.0,
+			l.Vserver, l.Name, l.Role, l.Address, l.CurrentNode, l.CurrentPort, l.HomeNode, l.HomePort)
+	}
+	c.scrapeCounter.Collect(ch)
+	c.scrapeFailureCounter.Collect(ch)
+	c.scrapeDurationGauge.Collect(ch)
+}
+
+func (c *NetworkCollector) Fetch() ([]*netapp.NetPort, []*netapp.NetInterface) {
+	start := time.Now()
+	ports, err := c.client.ListNetPorts()
+	var lifs []*netapp.NetInterface
+	if err == nil {
+		lifs, err = c.client.ListNetInterfaces()
+	}
+	elapsed := time.Now().Sub(start)
+	c.scrapeCounter.Inc()
+	c.scrapeDurationGauge.Set(elapsed.Seconds())
+	c.status.reportScrape("network", err)
+	if err != nil {
+		log.WithField("filer", c.filerName).WithError(err).Error("list network ports and interfaces failed")
+		c.scrapeFailureCounter.Inc()
+		return nil, nil
+	}
+	return ports, lifs
+}
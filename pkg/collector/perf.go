@@ -0,0 +1,45 @@
+package collector
+
+import (
+	"math"
+)
+
+// perfCounterDelta returns the increase of a cumulative perf counter between
+// two samples. A counter close to the maximum of uint64 is assumed to have
+// wrapped around; any other decrease means the counter was reset, e.g. because
+// the node rebooted, and ok is false.
+func perfCounterDelta(prev, cur uint64) (delta float64, ok bool) {
+	if cur >= prev {
+		return float64(cur - prev), true
+	}
+	if prev > math.MaxUint64/2 && cur < math.MaxUint64/2 {
+		return float64(math.MaxUint64-prev) + float64(cur) + 1, true
+	}
+	return 0, false
+}
+
+// perfRate returns the per second rate of a cumulative perf counter.
+func perfRate(prev, cur uint64, seconds float64) (float64, bool) {
+	delta, ok := perfCounterDelta(prev, cur)
+	if !ok || seconds <= 0 {
+		return 0, false
+	}
+	return delta / seconds, true
+}
+
+// perfAverage returns the average of a perf counter over its base counter, e.g.
+// latency per operation. The average is 0 if the base has not increased.
+func perfAverage(prev, cur, prevBase, curBase uint64) (float64, bool) {
+	delta, ok := perfCounterDelta(prev, cur)
+	if !ok {
+		return 0, false
+	}
+	deltaBase, ok := perfCounterDelta(prevBase, curBase)
+	if !ok {
+		return 0, false
+	}
+	if deltaBase == 0 {
+		return 0, true
+	}
+	return delta / deltaBase, true
+}
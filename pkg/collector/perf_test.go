@@ -0,0 +1,76 @@
+package collector
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPerfCounterDelta(t *testing.T) {
+	tests := []struct {
+		name      string
+		prev, cur uint64
+		delta     float64
+		ok        bool
+	}{
+		{"increase", 100, 150, 50, true},
+		{"unchanged", 100, 100, 0, true},
+		{"wrap just under max", math.MaxUint64 - 10, 5, 16, true},
+		{"wrap at max", math.MaxUint64, 0, 1, true},
+		{"reset", 1000, 10, 0, false},
+		{"reset to zero", 1000, 0, 0, false},
+	}
+	for _, tt := range tests {
+		delta, ok := perfCounterDelta(tt.prev, tt.cur)
+		if delta != tt.delta || ok != tt.ok {
+			t.Errorf("%s: perfCounterDelta(%d, %d) = (%v, %v), want (%v, %v)",
+				tt.name, tt.prev, tt.cur, delta, ok, tt.delta, tt.ok)
+		}
+	}
+}
+
+func TestPerfRate(t *testing.T) {
+	tests := []struct {
+		name      string
+		prev, cur uint64
+		seconds   float64
+		rate      float64
+		ok        bool
+	}{
+		{"increase", 100, 700, 60, 10, true},
+		{"wrap just under max", math.MaxUint64 - 9, 20, 10, 3, true},
+		{"reset", 700, 100, 60, 0, false},
+		{"zero elapsed time", 100, 700, 0, 0, false},
+		{"negative elapsed time", 100, 700, -1, 0, false},
+	}
+	for _, tt := range tests {
+		rate, ok := perfRate(tt.prev, tt.cur, tt.seconds)
+		if rate != tt.rate || ok != tt.ok {
+			t.Errorf("%s: perfRate(%d, %d, %v) = (%v, %v), want (%v, %v)",
+				tt.name, tt.prev, tt.cur, tt.seconds, rate, ok, tt.rate, tt.ok)
+		}
+	}
+}
+
+func TestPerfAverage(t *testing.T) {
+	tests := []struct {
+		name                         string
+		prev, cur, prevBase, curBase uint64
+		average                      float64
+		ok                           bool
+	}{
+		{"increase", 1000, 3000, 10, 20, 200, true},
+		{"zero base delta", 1000, 1000, 10, 10, 0, true},
+		{"zero base delta with counter increase", 1000, 3000, 10, 10, 0, true},
+		{"counter wrap", math.MaxUint64 - 99, 100, 10, 20, 20, true},
+		{"base wrap", 1000, 3000, math.MaxUint64 - 4, 5, 200, true},
+		{"counter reset", 3000, 1000, 10, 20, 0, false},
+		{"base reset", 1000, 3000, 20, 10, 0, false},
+	}
+	for _, tt := range tests {
+		average, ok := perfAverage(tt.prev, tt.cur, tt.prevBase, tt.curBase)
+		if average != tt.average || ok != tt.ok {
+			t.Errorf("%s: perfAverage(%d, %d, %d, %d) = (%v, %v), want (%v, %v)",
+				tt.name, tt.prev, tt.cur, tt.prevBase, tt.curBase, average, ok, tt.average, tt.ok)
+		}
+	}
+}
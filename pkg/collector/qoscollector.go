@@ -0,0 +1,218 @@
+package collector
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sapcc/netapp-api-exporter/pkg/netapp"
+	log "github.com/sirupsen/logrus"
+)
+
+type QosCollector struct {
+	client               *netapp.Client
+	filerName            string
+	status               *FilerStatus
+	policyGroupMetrics   []QosPolicyGroupMetric
+	perfMetrics          []QosPolicyGroupPerfMetric
+	samples              map[string]*netapp.QosPolicyGroupPerf
+	scrapeCounter        prometheus.Counter
+	scrapeFailureCounter prometheus.Counter
+	perfFailureCounter   prometheus.Counter
+	scrapeDurationGauge  prometheus.Gauge
+	mux                  sync.Mutex
+}
+
+type QosPolicyGroupMetric struct {
+	desc      *prometheus.Desc
+	valueType prometheus.ValueType
+	getterFn  func(g *netapp.QosPolicyGroup) float64
+}
+
+type QosPolicyGroupPerfMetric struct {
+	desc      *prometheus.Desc
+	valueType prometheus.ValueType
+	getterFn  func(r *qosPolicyGroupPerfRates) float64
+}
+
+// qosPolicyGroupPerfRates are computed from two consecutive samples of qos
+// policy group perf counters.
+type qosPolicyGroupPerfRates struct {
+	Ops        float64
+	Throughput float64
+	Latency    float64
+}
+
+func NewQosCollector(client *netapp.Client, filerName string, status *FilerStatus) *QosCollector {
+	policyGroupLabels := []string{"vserver", "policy_group", "policy_group_class"}
+	policyGroupMetrics := []QosPolicyGroupMetric{
+		{
+			desc:      prometheus.NewDesc("netapp_qos_policy_group_max_throughput_iops", "Netapp QoS Policy Group: maximum throughput in iops; not exported if unlimited", policyGroupLabels, nil),
+			valueType: prometheus.GaugeValue,
+			getterFn:  func(g *netapp.QosPolicyGroup) float64 { return g.MaxThroughputIOPS },
+		}, {
+			desc:      prometheus.NewDesc("netapp_qos_policy_group_max_throughput_bytes", "Netapp QoS Policy Group: maximum throughput in bytes per second; not exported if unlimited", policyGroupLabels, nil),
+			valueType: prometheus.GaugeValue,
+			getterFn:  func(g *netapp.QosPolicyGroup) float64 { return g.MaxThroughputBytes },
+		}, {
+			desc:      prometheus.NewDesc("netapp_qos_policy_group_min_throughput_iops", "Netapp QoS Policy Group: minimum throughput in iops; not exported if not set", policyGroupLabels, nil),
+			valueType: prometheus.GaugeValue,
+			getterFn:  func(g *netapp.QosPolicyGroup) float64 { return g.MinThroughputIOPS },
+		}, {
+			desc:      prometheus.NewDesc("netapp_qos_policy_group_min_throughput_bytes", "Netapp QoS Policy Group: minimum throughput in bytes per second; not exported if not set", policyGroupLabels, nil),
+			valueType: prometheus.GaugeValue,
+			getterFn:  func(g *netapp.QosPolicyGroup) float64 { return g.MinThroughputBytes },
+		}, {
+			desc:      prometheus.NewDesc("netapp_qos_policy_group_workloads", "Netapp QoS Policy Group: number of workloads attached to the policy group", policyGroupLabels, nil),
+			valueType: prometheus.GaugeValue,
+			getterFn:  func(g *netapp.QosPolicyGroup) float64 { return g.NumWorkloads },
+		},
+	}
+	perfMetrics := []QosPolicyGroupPerfMetric{
+		{
+			desc:      prometheus.NewDesc("netapp_qos_policy_group_ops_per_second", "Netapp QoS Policy Group Perf: operations per second", policyGroupLabels, nil),
+			valueType: prometheus.GaugeValue,
+			getterFn:  func(r *qosPolicyGroupPerfRates) float64 { return r.Ops },
+		}, {
+			desc:      prometheus.NewDesc("netapp_qos_policy_group_bytes_per_second", "Netapp QoS Policy Group Perf: read and write throughput", policyGroupLabels, nil),
+			valueType: prometheus.GaugeValue,
+			getterFn:  func(r *qosPolicyGroupPerfRates) float64 { return r.Throughput },
+		}, {
+			desc:      prometheus.NewDesc("netapp_qos_policy_group_latency_seconds", "Netapp QoS Policy Group Perf: average latency per operation", policyGroupLabels, nil),
+			valueType: prometheus.GaugeValue,
+			getterFn:  func(r *qosPolicyGroupPerfRates) float64 { return r.Latency },
+		},
+	}
+	scrapeDurationGauge := prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "netapp_qos_scrape_duration_seconds",
+			Help: "duration in seconds of fetching qos policy groups and their perf counters from filer",
+		},
+	)
+	scrapeCounter := prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "netapp_qos_scrape_total",
+			Help: "number of qos policy group fetches from filer",
+		},
+	)
+	scrapeFailureCounter := prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "netapp_qos_scrape_failure_total",
+			Help: "number of failures for fetching qos policy groups from filer",
+		},
+	)
+	perfFailureCounter := prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "netapp_qos_perf_scrape_failure_total",
+			Help: "number of failures for fetching qos policy group perf counters from filer",
+		},
+	)
+	return &QosCollector{
+		client:               client,
+		filerName:            filerName,
+		status:               status,
+		policyGroupMetrics:   policyGroupMetrics,
+		perfMetrics:          perfMetrics,
+		scrapeCounter:        scrapeCounter,
+		scrapeFailureCounter: scrapeFailureCounter,
+		perfFailureCounter:   perfFailureCounter,
+		scrapeDurationGauge:  scrapeDurationGauge,
+	}
+}
+
+func (c *QosCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, m := range c.policyGroupMetrics {
+		ch <- m.desc
+	}
+	for _, m := range c.perfMetrics {
+		ch <- m.desc
+	}
+	ch <- c.scrapeCounter.Desc()
+	ch <- c.scrapeFailureCounter.Desc()
+	ch <- c.perfFailureCounter.Desc()
+	ch <- c.scrapeDurationGauge.Desc()
+}
+
+func (c *QosCollector) Collect(ch chan<- prometheus.Metric) {
+	defer c.mux.Unlock()
+	c.mux.Lock()
+
+	// Rates are computed against the previous sample of each policy group, as
+	// done for the volume perf metrics.
+	groups, perfs := c.Fetch()
+	rates := make(map[string]*qosPolicyGroupPerfRates)
+	if len(perfs) > 0 {
+		samples := make(map[string]*netapp.QosPolicyGroupPerf, len(perfs))
+		for _, p := range perfs {
+			samples[p.PolicyGroup] = p
+			if prev, ok := c.samples[p.PolicyGroup]; ok {
+				if r, ok := computeQosPolicyGroupPerfRates(prev, p); ok {
+					rates[p.PolicyGroup] = r
+				}
+			}
+		}
+		c.samples = samples
+	}
+
+	for _, g := range groups {
+		labels := []string{g.Vserver, g.Name, g.Class}
+		for _, m := range c.policyGroupMetrics {
+			v := m.getterFn(g)
+			if v < 0 {
+				continue
+			}
+			ch <- prometheus.MustNewConstMetric(m.desc, m.valueType, v, labels...)
+		}
+		if r, ok := rates[g.Name]; ok {
+			for _, m := range c.perfMetrics {
+				ch <- prometheus.MustNewConstMetric(m.desc, m.valueType, m.getterFn(r), labels...)
+			}
+		}
+	}
+	c.scrapeCounter.Collect(ch)
+	c.scrapeFailureCounter.Collect(ch)
+	c.perfFailureCounter.Collect(ch)
+	c.scrapeDurationGauge.Collect(ch)
+}
+
+func (c *QosCollector) Fetch() ([]*netapp.QosPolicyGroup, []*netapp.QosPolicyGroupPerf) {
+	start := time.Now()
+	groups, err := c.client.ListQosPolicyGroups()
+	var perfs []*netapp.QosPolicyGroupPerf
+	if err == nil {
+		// the policy groups are exported without the perf metrics if the
+		// perf counters cannot be fetched
+		var perfErr error
+		if perfs, perfErr = c.client.ListQosPolicyGroupPerf(); perfErr != nil {
+			log.WithField("filer", c.filerName).WithError(perfErr).Error("list qos policy group perf failed")
+			c.perfFailureCounter.Inc()
+		}
+	}
+	elapsed := time.Now().Sub(start)
+	c.scrapeCounter.Inc()
+	c.scrapeDurationGauge.Set(elapsed.Seconds())
+	c.status.reportScrape("qos", err)
+	if err != nil {
+		log.WithField("filer", c.filerName).WithError(err).Error("list qos policy groups failed")
+		c.scrapeFailureCounter.Inc()
+		return nil, nil
+	}
+	return groups, perfs
+}
+
+// computeQosPolicyGroupPerfRates returns false if any of the counters has been
+// reset since the previous sample.
+func computeQosPolicyGroupPerfRates(prev, cur *netapp.QosPolicyGroupPerf) (*qosPolicyGroupPerfRates, bool) {
+	seconds := cur.Timestamp.Sub(prev.Timestamp).Seconds()
+	ops, opsOk := perfRate(prev.Ops, cur.Ops, seconds)
+	read, readOk := perfRate(prev.ReadData, cur.ReadData, seconds)
+	write, writeOk := perfRate(prev.WriteData, cur.WriteData, seconds)
+	// latency counter is in microseconds
+	latency, latencyOk := perfAverage(prev.Latency, cur.Latency, prev.Ops, cur.Ops)
+	r := &qosPolicyGroupPerfRates{
+		Ops:        ops,
+		Throughput: read + write,
+		Latency:    latency / 1e6,
+	}
+	return r, opsOk && readOk && writeOk && latencyOk
+}
@@ -0,0 +1,92 @@
+package collector
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sapcc/netapp-api-exporter/pkg/netapp"
+	log "github.com/sirupsen/logrus"
+)
+
+type QtreeCollector struct {
+	client               *netapp.Client
+	filerName            string
+	status               *FilerStatus
+	qtreeInfoDesc        *prometheus.Desc
+	scrapeCounter        prometheus.Counter
+	scrapeFailureCounter prometheus.Counter
+	scrapeDurationGauge  prometheus.Gauge
+}
+
+// NewQtreeCollector returns a collector for qtrees. The openstack labels are
+// taken from the qtree's own comment, not from the containing volume, since a
+// qtree backed share is a different share than the one of its volume.
+func NewQtreeCollector(client *netapp.Client, filerName string, status *FilerStatus) *QtreeCollector {
+	scrapeDurationGauge := prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "netapp_qtree_scrape_duration_seconds",
+			Help: "duration in seconds of fetching qtrees from filer",
+		},
+	)
+	scrapeCounter := prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "netapp_qtree_scrape_total",
+			Help: "number of qtree fetches from filer",
+		},
+	)
+	scrapeFailureCounter := prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "netapp_qtree_scrape_failure_total",
+			Help: "number of failures for fetching qtrees from filer",
+		},
+	)
+	return &QtreeCollector{
+		client:    client,
+		filerName: filerName,
+		status:    status,
+		qtreeInfoDesc: prometheus.NewDesc(
+			"netapp_qtree_info",
+			"Netapp Qtree: info about the qtree in labels",
+			[]string{"vserver", "volume", "qtree", "security_style", "oplocks", "export_policy", "status",
+				"project_id", "share_id", "share_name", "share_type"},
+			nil),
+		scrapeDurationGauge:  scrapeDurationGauge,
+		scrapeCounter:        scrapeCounter,
+		scrapeFailureCounter: scrapeFailureCounter,
+	}
+}
+
+func (c *QtreeCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.qtreeInfoDesc
+	ch <- c.scrapeCounter.Desc()
+	ch <- c.scrapeFailureCounter.Desc()
+	ch <- c.scrapeDurationGauge.Desc()
+}
+
+func (c *QtreeCollector) Collect(ch chan<- prometheus.Metric) {
+	qtrees := c.Fetch()
+
+	for _, q := range qtrees {
+		ch <- prometheus.MustNewConstMetric(c.qtreeInfoDesc, prometheus.GaugeValue, 1.0,
+			q.Vserver, q.Volume, q.Qtree, q.SecurityStyle, q.Oplocks, q.ExportPolicy, q.Status,
+			q.ProjectID, q.ShareID, q.ShareName, q.ShareType)
+	}
+	c.scrapeCounter.Collect(ch)
+	c.scrapeFailureCounter.Collect(ch)
+	c.scrapeDurationGauge.Collect(ch)
+}
+
+func (c *QtreeCollector) Fetch() []*netapp.Qtree {
+	start := time.Now()
+	qtrees, err := c.client.ListQtrees()
+	elapsed := time.Now().Sub(start)
+	c.scrapeCounter.Inc()
+	c.scrapeDurationGauge.Set(elapsed.Seconds())
+	c.status.reportScrape("qtree", err)
+	if err != nil {
+		log.WithField("filer", c.filerName).WithError(err).Error("list qtrees failed")
+		c.scrapeFailureCounter.Inc()
+		return nil
+	}
+	return qtrees
+}
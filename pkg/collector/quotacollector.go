@@ -0,0 +1,152 @@
+package collector
+
+import (
+	"time"
+
+	n "github.com/pepabo/go-netapp/netapp"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sapcc/netapp-api-exporter/pkg/netapp"
+	log "github.com/sirupsen/logrus"
+)
+
+var quotaStates = map[string]float64{
+	n.QuotaStatusOn:           1,
+	n.QuotaStatusOff:          2,
+	n.QuotaStatusInitializing: 3,
+	n.QuotaStatusCorrupt:      4,
+	n.QuotaStatusResizing:     5,
+	n.QuotaStatusReverting:    6,
+	n.QuotaStatusUpgrading:    7,
+	n.QuotaStatusMixed:        8,
+}
+
+type QuotaCollector struct {
+	client               *netapp.Client
+	filerName            string
+	status               *FilerStatus
+	quotaMetrics         []QuotaMetric
+	quotaStateDesc       *prometheus.Desc
+	scrapeCounter        prometheus.Counter
+	scrapeFailureCounter prometheus.Counter
+	scrapeDurationGauge  prometheus.Gauge
+}
+
+type QuotaMetric struct {
+	desc      *prometheus.Desc
+	valueType prometheus.ValueType
+	getterFn  func(q *netapp.Quota) float64
+}
+
+func NewQuotaCollector(client *netapp.Client, filerName string, status *FilerStatus) *QuotaCollector {
+	quotaLabels := []string{"vserver", "volume", "qtree", "quota_type", "quota_target"}
+	quotaMetrics := []QuotaMetric{
+		{
+			desc:      prometheus.NewDesc("netapp_quota_disk_used_bytes", "Netapp Quota: disk space used", quotaLabels, nil),
+			valueType: prometheus.GaugeValue,
+			getterFn:  func(q *netapp.Quota) float64 { return q.DiskUsed },
+		}, {
+			desc:      prometheus.NewDesc("netapp_quota_disk_limit_bytes", "Netapp Quota: disk space limit; not exported if unlimited", quotaLabels, nil),
+			valueType: prometheus.GaugeValue,
+			getterFn:  func(q *netapp.Quota) float64 { return q.DiskLimit },
+		}, {
+			desc:      prometheus.NewDesc("netapp_quota_soft_disk_limit_bytes", "Netapp Quota: soft disk space limit; not exported if unlimited", quotaLabels, nil),
+			valueType: prometheus.GaugeValue,
+			getterFn:  func(q *netapp.Quota) float64 { return q.SoftDiskLimit },
+		}, {
+			desc:      prometheus.NewDesc("netapp_quota_files_used", "Netapp Quota: number of files used", quotaLabels, nil),
+			valueType: prometheus.GaugeValue,
+			getterFn:  func(q *netapp.Quota) float64 { return q.FilesUsed },
+		}, {
+			desc:      prometheus.NewDesc("netapp_quota_file_limit", "Netapp Quota: file limit; not exported if unlimited", quotaLabels, nil),
+			valueType: prometheus.GaugeValue,
+			getterFn:  func(q *netapp.Quota) float64 { return q.FileLimit },
+		}, {
+			desc:      prometheus.NewDesc("netapp_quota_soft_file_limit", "Netapp Quota: soft file limit; not exported if unlimited", quotaLabels, nil),
+			valueType: prometheus.GaugeValue,
+			getterFn:  func(q *netapp.Quota) float64 { return q.SoftFileLimit },
+		},
+	}
+	scrapeDurationGauge := prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "netapp_quota_scrape_duration_seconds",
+			Help: "duration in seconds of fetching quotas from filer",
+		},
+	)
+	scrapeCounter := prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "netapp_quota_scrape_total",
+			Help: "number of quota fetches from filer",
+		},
+	)
+	scrapeFailureCounter := prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "netapp_quota_scrape_failure_total",
+			Help: "number of failures for fetching quotas from filer",
+		},
+	)
+	return &QuotaCollector{
+		client:       client,
+		filerName:    filerName,
+		status:       status,
+		quotaMetrics: quotaMetrics,
+		quotaStateDesc: prometheus.NewDesc(
+			"netapp_volume_quota_state",
+			"Netapp Volume: quota state (1: on; 2: off; 3: initializing; 4: corrupt; 5: resizing; "+
+				"6: reverting; 7: upgrading; 8: mixed; 0: unknown)",
+			[]string{"vserver", "volume"},
+			nil),
+		scrapeDurationGauge:  scrapeDurationGauge,
+		scrapeCounter:        scrapeCounter,
+		scrapeFailureCounter: scrapeFailureCounter,
+	}
+}
+
+func (c *QuotaCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, m := range c.quotaMetrics {
+		ch <- m.desc
+	}
+	ch <- c.quotaStateDesc
+	ch <- c.scrapeCounter.Desc()
+	ch <- c.scrapeFailureCounter.Desc()
+	ch <- c.scrapeDurationGauge.Desc()
+}
+
+func (c *QuotaCollector) Collect(ch chan<- prometheus.Metric) {
+	quotas, statuses := c.Fetch()
+
+	for _, q := range quotas {
+		labels := []string{q.Vserver, q.Volume, q.Qtree, q.Type, q.Target}
+		for _, m := range c.quotaMetrics {
+			v := m.getterFn(q)
+			if v < 0 {
+				continue
+			}
+			ch <- prometheus.MustNewConstMetric(m.desc, m.valueType, v, labels...)
+		}
+	}
+	for _, s := range statuses {
+		ch <- prometheus.MustNewConstMetric(c.quotaStateDesc, prometheus.GaugeValue, quotaStates[s.Status], s.Vserver, s.Volume)
+	}
+	c.scrapeCounter.Collect(ch)
+	c.scrapeFailureCounter.Collect(ch)
+	c.scrapeDurationGauge.Collect(ch)
+}
+
+func (c *QuotaCollector) Fetch() ([]*netapp.Quota, []*netapp.VolumeQuotaStatus) {
+	start := time.Now()
+	quotas, err := c.client.ListQuotas()
+	var statuses []*netapp.VolumeQuotaStatus
+	if err == nil {
+		statuses, err = c.client.ListVolumeQuotaStatus()
+	}
+	elapsed := time.Now().Sub(start)
+	c.scrapeCounter.Inc()
+	c.scrapeDurationGauge.Set(elapsed.Seconds())
+	c.status.reportScrape("quota", err)
+	if err != nil {
+		log.WithField("filer", c.filerName).WithError(err).Error("list quotas failed")
+		c.scrapeFailureCounter.Inc()
+		return nil, nil
+	}
+	return quotas, statuses
+}
@@ -0,0 +1,182 @@
+package collector
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sapcc/netapp-api-exporter/pkg/netapp"
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	snapmirrorMirrorStates = map[string]float64{
+		"snapmirrored":  1,
+		"uninitialized": 2,
+		"broken-off":    3,
+	}
+	snapmirrorRelationshipStatuses = map[string]float64{
+		"idle":         1,
+		"transferring": 2,
+		"checking":     3,
+		"quiescing":    4,
+		"quiesced":     5,
+		"queued":       6,
+		"preparing":    7,
+		"finalizing":   8,
+		"aborting":     9,
+		"breaking":     10,
+	}
+)
+
+type SnapmirrorCollector struct {
+	client               *netapp.Client
+	filerName            string
+	status               *FilerStatus
+	snapmirrorMetrics    []SnapmirrorMetric
+	lagTimeDesc          *prometheus.Desc
+	scrapeCounter        prometheus.Counter
+	scrapeFailureCounter prometheus.Counter
+	scrapeDurationGauge  prometheus.Gauge
+}
+
+type SnapmirrorMetric struct {
+	desc      *prometheus.Desc
+	valueType prometheus.ValueType
+	getterFn  func(r *netapp.SnapmirrorRelationship) float64
+}
+
+func NewSnapmirrorCollector(client *netapp.Client, filerName string, status *FilerStatus) *SnapmirrorCollector {
+	snapmirrorLabels := []string{"source_vserver", "source_volume", "destination_vserver", "destination_volume", "destination_node", "relationship_type"}
+	snapmirrorMetrics := []SnapmirrorMetric{
+		{
+			desc: prometheus.NewDesc(
+				"netapp_snapmirror_last_transfer_size_bytes",
+				"Netapp Snapmirror Metrics: size of the last transfer",
+				snapmirrorLabels,
+				nil),
+			valueType: prometheus.GaugeValue,
+			getterFn:  func(r *netapp.SnapmirrorRelationship) float64 { return r.LastTransferSize },
+		}, {
+			desc: prometheus.NewDesc(
+				"netapp_snapmirror_last_transfer_duration_seconds",
+				"Netapp Snapmirror Metrics: duration of the last transfer",
+				snapmirrorLabels,
+				nil),
+			valueType: prometheus.GaugeValue,
+			getterFn:  func(r *netapp.SnapmirrorRelationship) float64 { return r.LastTransferDuration },
+		}, {
+			desc: prometheus.NewDesc(
+				"netapp_snapmirror_last_transfer_end_timestamp_seconds",
+				"Netapp Snapmirror Metrics: end time of the last transfer",
+				snapmirrorLabels,
+				nil),
+			valueType: prometheus.GaugeValue,
+			getterFn:  func(r *netapp.SnapmirrorRelationship) float64 { return r.LastTransferEndTimestamp },
+		}, {
+			desc: prometheus.NewDesc(
+				"netapp_snapmirror_mirror_state",
+				"Netapp Snapmirror Metrics: mirror state (1: snapmirrored; 2: uninitialized; 3: broken-off; 0: unknown)",
+				snapmirrorLabels,
+				nil),
+			valueType: prometheus.GaugeValue,
+			getterFn:  func(r *netapp.SnapmirrorRelationship) float64 { return snapmirrorMirrorStates[r.MirrorState] },
+		}, {
+			desc: prometheus.NewDesc(
+				"netapp_snapmirror_relationship_status",
+				"Netapp Snapmirror Metrics: relationship status (1: idle; 2: transferring; 3: checking; 4: quiescing; "+
+					"5: quiesced; 6: queued; 7: preparing; 8: finalizing; 9: aborting; 10: breaking; 0: unknown)",
+				snapmirrorLabels,
+				nil),
+			valueType: prometheus.GaugeValue,
+			getterFn: func(r *netapp.SnapmirrorRelationship) float64 {
+				return snapmirrorRelationshipStatuses[r.RelationshipStatus]
+			},
+		}, {
+			desc:      prometheus.NewDesc("netapp_snapmirror_is_healthy", "Netapp Snapmirror Metrics: is healthy", snapmirrorLabels, nil),
+			valueType: prometheus.GaugeValue,
+			getterFn: func(r *netapp.SnapmirrorRelationship) float64 {
+				if r.IsHealthy {
+					return 1.0
+				}
+				return 0.0
+			},
+		},
+	}
+	scrapeDurationGauge := prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "netapp_snapmirror_scrape_duration_seconds",
+			Help: "duration in seconds of fetching snapmirror relationships from filer",
+		},
+	)
+	scrapeCounter := prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "netapp_snapmirror_scrape_total",
+			Help: "number of snapmirror relationship fetches from filer",
+		},
+	)
+	scrapeFailureCounter := prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "netapp_snapmirror_scrape_failure_total",
+			Help: "number of failures for fetching snapmirror relationships from filer",
+		},
+	)
+	return &SnapmirrorCollector{
+		client:            client,
+		filerName:         filerName,
+		status:            status,
+		snapmirrorMetrics: snapmirrorMetrics,
+		lagTimeDesc: prometheus.NewDesc(
+			"netapp_snapmirror_lag_time_seconds",
+			"Netapp Snapmirror Metrics: time since the exported snapshot was created",
+			snapmirrorLabels,
+			nil),
+		scrapeDurationGauge:  scrapeDurationGauge,
+		scrapeCounter:        scrapeCounter,
+		scrapeFailureCounter: scrapeFailureCounter,
+	}
+}
+
+func (c *SnapmirrorCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, m := range c.snapmirrorMetrics {
+		ch <- m.desc
+	}
+	ch <- c.lagTimeDesc
+	ch <- c.scrapeCounter.Desc()
+	ch <- c.scrapeFailureCounter.Desc()
+	ch <- c.scrapeDurationGauge.Desc()
+}
+
+func (c *SnapmirrorCollector) Collect(ch chan<- prometheus.Metric) {
+	relationships := c.Fetch()
+
+	for _, r := range relationships {
+		labels := []string{r.SourceVserver, r.SourceVolume, r.DestinationVserver, r.DestinationVolume,
+			r.DestinationNode, r.RelationshipType}
+		for _, m := range c.snapmirrorMetrics {
+			ch <- prometheus.MustNewConstMetric(m.desc, m.valueType, m.getterFn(r), labels...)
+		}
+		// uninitialized relationships have no lag time, which must not be
+		// exported as 0
+		if r.LagTime >= 0 {
+			ch <- prometheus.MustNewConstMetric(c.lagTimeDesc, prometheus.GaugeValue, r.LagTime, labels...)
+		}
+	}
+	c.scrapeCounter.Collect(ch)
+	c.scrapeFailureCounter.Collect(ch)
+	c.scrapeDurationGauge.Collect(ch)
+}
+
+func (c *SnapmirrorCollector) Fetch() []*netapp.SnapmirrorRelationship {
+	start := time.Now()
+	relationships, err := c.client.ListSnapmirrorRelationships()
+	elapsed := time.Now().Sub(start)
+	c.scrapeCounter.Inc()
+	c.scrapeDurationGauge.Set(elapsed.Seconds())
+	c.status.reportScrape("snapmirror", err)
+	if err != nil {
+		log.WithField("filer", c.filerName).WithError(err).Error("list snapmirror relationships failed")
+		c.scrapeFailureCounter.Inc()
+		return nil
+	}
+	return relationships
+}
@@ -0,0 +1,226 @@
+package collector
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sapcc/netapp-api-exporter/pkg/netapp"
+	log "github.com/sirupsen/logrus"
+)
+
+type SnapshotCollector struct {
+	client               *netapp.Client
+	filerName            string
+	status               *FilerStatus
+	volumeCollector      *VolumeCollector
+	perSnapshot          bool
+	volumeMetrics        []VolumeSnapshotMetric
+	snapshotMetrics      []SnapshotMetric
+	scrapeCounter        prometheus.Counter
+	scrapeFailureCounter prometheus.Counter
+	scrapeDurationGauge  prometheus.Gauge
+}
+
+type VolumeSnapshotMetric struct {
+	desc      *prometheus.Desc
+	valueType prometheus.ValueType
+	getterFn  func(s *volumeSnapshots) float64
+	// not exported for volumes without snapshots
+	skipEmpty bool
+}
+
+type SnapshotMetric struct {
+	desc      *prometheus.Desc
+	valueType prometheus.ValueType
+	getterFn  func(s *netapp.Snapshot) float64
+}
+
+// volumeSnapshots summarizes the snapshots of a volume.
+type volumeSnapshots struct {
+	vserver         string
+	volume          string
+	count           float64
+	oldest          float64
+	newest          float64
+	size            float64
+	busyCount       float64
+	dependencyCount float64
+}
+
+// NewSnapshotCollector returns a collector for snapshots summarized per volume.
+// The volumes are taken from the volume collector's cache, so that volumes
+// without snapshots are exported with a count of 0. With perSnapshot, metrics
+// for every single snapshot are exported as well.
+func NewSnapshotCollector(client *netapp.Client, filerName string, status *FilerStatus, volumeCollector *VolumeCollector, perSnapshot bool) *SnapshotCollector {
+	volumeLabels := []string{"vserver", "volume"}
+	volumeMetrics := []VolumeSnapshotMetric{
+		{
+			desc:      prometheus.NewDesc("netapp_volume_snapshot_count", "Netapp Volume Snapshots: number of snapshots", volumeLabels, nil),
+			valueType: prometheus.GaugeValue,
+			getterFn:  func(s *volumeSnapshots) float64 { return s.count },
+		}, {
+			desc:      prometheus.NewDesc("netapp_volume_snapshot_oldest_timestamp_seconds", "Netapp Volume Snapshots: creation time of the oldest snapshot", volumeLabels, nil),
+			valueType: prometheus.GaugeValue,
+			getterFn:  func(s *volumeSnapshots) float64 { return s.oldest },
+			skipEmpty: true,
+		}, {
+			desc:      prometheus.NewDesc("netapp_volume_snapshot_newest_timestamp_seconds", "Netapp Volume Snapshots: creation time of the newest snapshot", volumeLabels, nil),
+			valueType: prometheus.GaugeValue,
+			getterFn:  func(s *volumeSnapshots) float64 { return s.newest },
+			skipEmpty: true,
+		}, {
+			desc:      prometheus.NewDesc("netapp_volume_snapshot_bytes", "Netapp Volume Snapshots: space exclusively used by all snapshots", volumeLabels, nil),
+			valueType: prometheus.GaugeValue,
+			getterFn:  func(s *volumeSnapshots) float64 { return s.size },
+		}, {
+			desc:      prometheus.NewDesc("netapp_volume_snapshot_busy_count", "Netapp Volume Snapshots: number of busy snapshots", volumeLabels, nil),
+			valueType: prometheus.GaugeValue,
+			getterFn:  func(s *volumeSnapshots) float64 { return s.busyCount },
+		}, {
+			desc:      prometheus.NewDesc("netapp_volume_snapshot_dependency_count", "Netapp Volume Snapshots: number of snapshots with dependencies (e.g. snapmirror, vclone)", volumeLabels, nil),
+			valueType: prometheus.GaugeValue,
+			getterFn:  func(s *volumeSnapshots) float64 { return s.dependencyCount },
+		},
+	}
+	snapshotLabels := []string{"vserver", "volume", "snapshot", "snapmirror_label"}
+	snapshotMetrics := []SnapshotMetric{
+		{
+			desc:      prometheus.NewDesc("netapp_snapshot_creation_timestamp_seconds", "Netapp Snapshot: creation time", snapshotLabels, nil),
+			valueType: prometheus.GaugeValue,
+			getterFn:  func(s *netapp.Snapshot) float64 { return s.CreationTime },
+		}, {
+			desc:      prometheus.NewDesc("netapp_snapshot_bytes", "Netapp Snapshot: space exclusively used by the snapshot", snapshotLabels, nil),
+			valueType: prometheus.GaugeValue,
+			getterFn:  func(s *netapp.Snapshot) float64 { return s.Size },
+		}, {
+			desc:      prometheus.NewDesc("netapp_snapshot_busy", "Netapp Snapshot: is busy", snapshotLabels, nil),
+			valueType: prometheus.GaugeValue,
+			getterFn: func(s *netapp.Snapshot) float64 {
+				if s.Busy {
+					return 1.0
+				}
+				return 0.0
+			},
+		},
+	}
+	scrapeDurationGauge := prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "netapp_snapshot_scrape_duration_seconds",
+			Help: "duration in seconds of fetching snapshots from filer",
+		},
+	)
+	scrapeCounter := prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "netapp_snapshot_scrape_total",
+			Help: "number of snapshot fetches from filer",
+		},
+	)
+	scrapeFailureCounter := prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "netapp_snapshot_scrape_failure_total",
+			Help: "number of failures for fetching snapshots from filer",
+		},
+	)
+	return &SnapshotCollector{
+		client:               client,
+		filerName:            filerName,
+		status:               status,
+		volumeCollector:      volumeCollector,
+		perSnapshot:          perSnapshot,
+		volumeMetrics:        volumeMetrics,
+		snapshotMetrics:      snapshotMetrics,
+		scrapeDurationGauge:  scrapeDurationGauge,
+		scrapeCounter:        scrapeCounter,
+		scrapeFailureCounter: scrapeFailureCounter,
+	}
+}
+
+func (c *SnapshotCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, m := range c.volumeMetrics {
+		ch <- m.desc
+	}
+	if c.perSnapshot {
+		for _, m := range c.snapshotMetrics {
+			ch <- m.desc
+		}
+	}
+	ch <- c.scrapeCounter.Desc()
+	ch <- c.scrapeFailureCounter.Desc()
+	ch <- c.scrapeDurationGauge.Desc()
+}
+
+func (c *SnapshotCollector) Collect(ch chan<- prometheus.Metric) {
+	snapshots, err := c.Fetch()
+	// without snapshots, every volume would be reported with a count of 0
+	if err == nil {
+		for _, v := range summarizeSnapshots(c.volumeCollector.Volumes(), snapshots) {
+			for _, m := range c.volumeMetrics {
+				if m.skipEmpty && v.count == 0 {
+					continue
+				}
+				ch <- prometheus.MustNewConstMetric(m.desc, m.valueType, m.getterFn(v), v.vserver, v.volume)
+			}
+		}
+	}
+	if c.perSnapshot {
+		for _, s := range snapshots {
+			labels := []string{s.Vserver, s.Volume, s.Name, s.SnapmirrorLabel}
+			for _, m := range c.snapshotMetrics {
+				ch <- prometheus.MustNewConstMetric(m.desc, m.valueType, m.getterFn(s), labels...)
+			}
+		}
+	}
+	c.scrapeCounter.Collect(ch)
+	c.scrapeFailureCounter.Collect(ch)
+	c.scrapeDurationGauge.Collect(ch)
+}
+
+func (c *SnapshotCollector) Fetch() ([]*netapp.Snapshot, error) {
+	start := time.Now()
+	snapshots, err := c.client.ListSnapshots()
+	elapsed := time.Now().Sub(start)
+	c.scrapeCounter.Inc()
+	c.scrapeDurationGauge.Set(elapsed.Seconds())
+	c.status.reportScrape("snapshot", err)
+	if err != nil {
+		log.WithField("filer", c.filerName).WithError(err).Error("list snapshots failed")
+		c.scrapeFailureCounter.Inc()
+		return nil, err
+	}
+	return snapshots, nil
+}
+
+// summarizeSnapshots summarizes the snapshots per volume. The summaries are
+// seeded from the volumes, so that volumes without snapshots are included.
+func summarizeSnapshots(volumes []*netapp.Volume, snapshots []*netapp.Snapshot) map[string]*volumeSnapshots {
+	summaries := make(map[string]*volumeSnapshots, len(volumes))
+	for _, v := range volumes {
+		summaries[v.Vserver+"/"+v.Volume] = &volumeSnapshots{vserver: v.Vserver, volume: v.Volume}
+	}
+	for _, s := range snapshots {
+		key := s.Vserver + "/" + s.Volume
+		v, ok := summaries[key]
+		if !ok {
+			v = &volumeSnapshots{vserver: s.Vserver, volume: s.Volume}
+			summaries[key] = v
+		}
+		if v.count == 0 {
+			v.oldest, v.newest = s.CreationTime, s.CreationTime
+		}
+		v.count++
+		v.size += s.Size
+		if s.CreationTime < v.oldest {
+			v.oldest = s.CreationTime
+		}
+		if s.CreationTime > v.newest {
+			v.newest = s.CreationTime
+		}
+		if s.Busy {
+			v.busyCount++
+		}
+		if s.Dependency != "" {
+			v.dependencyCount++
+		}
+	}
+	return summaries
+}
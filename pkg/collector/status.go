@@ -0,0 +1,111 @@
+package collector
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sapcc/netapp-api-exporter/pkg/netapp"
+)
+
+// FilerStatus holds the status of a registered or probed filer. Whether the
+// filer is up is only derived from the checks of its reachability. The
+// collectors report the time of their last successful fetch and the reason of
+// their failures.
+type FilerStatus struct {
+	mux                sync.Mutex
+	up                 bool
+	lastErrorReason    string
+	lastSuccessfulTime map[string]time.Time
+}
+
+func NewFilerStatus() *FilerStatus {
+	return &FilerStatus{lastSuccessfulTime: make(map[string]time.Time)}
+}
+
+// ReportCheck updates the status with the result of checking the filer's
+// reachability. A successful check clears the reason of the last error.
+func (s *FilerStatus) ReportCheck(err error) {
+	defer s.mux.Unlock()
+	s.mux.Lock()
+
+	s.up = err == nil
+	s.lastErrorReason = ""
+	if err != nil {
+		s.lastErrorReason = netapp.ErrorReason(err)
+	}
+}
+
+// Up returns whether the last check of the filer was successful.
+func (s *FilerStatus) Up() bool {
+	defer s.mux.Unlock()
+	s.mux.Lock()
+	return s.up
+}
+
+// reportScrape updates the status with the result of a collector's fetch.
+func (s *FilerStatus) reportScrape(collectorName string, err error) {
+	defer s.mux.Unlock()
+	s.mux.Lock()
+
+	if err != nil {
+		s.lastErrorReason = netapp.ErrorReason(err)
+		return
+	}
+	s.lastSuccessfulTime[collectorName] = time.Now()
+}
+
+type FilerStatusCollector struct {
+	status                 *FilerStatus
+	upDesc                 *prometheus.Desc
+	lastSuccessfulTimeDesc *prometheus.Desc
+	lastErrorDesc          *prometheus.Desc
+}
+
+// NewFilerStatusCollector returns a collector which exports whether the filer
+// is up, the time of the last successful fetch of every collector, and the
+// reason of the last error.
+func NewFilerStatusCollector(status *FilerStatus) *FilerStatusCollector {
+	return &FilerStatusCollector{
+		status: status,
+		upDesc: prometheus.NewDesc(
+			"netapp_filer_up",
+			"Netapp Filer: whether the filer is reachable",
+			nil,
+			nil),
+		lastSuccessfulTimeDesc: prometheus.NewDesc(
+			"netapp_filer_last_successful_scrape_timestamp_seconds",
+			"Netapp Filer: time of the last successful fetch of the collector",
+			[]string{"collector"},
+			nil),
+		lastErrorDesc: prometheus.NewDesc(
+			"netapp_filer_last_error",
+			"Netapp Filer: reason of the last failed request to the filer since its last successful check (dns, auth, timeout, tls or api)",
+			[]string{"reason"},
+			nil),
+	}
+}
+
+func (c *FilerStatusCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.upDesc
+	ch <- c.lastSuccessfulTimeDesc
+	ch <- c.lastErrorDesc
+}
+
+func (c *FilerStatusCollector) Collect(ch chan<- prometheus.Metric) {
+	s := c.status
+	defer s.mux.Unlock()
+	s.mux.Lock()
+
+	var up float64
+	if s.up {
+		up = 1.0
+	}
+	ch <- prometheus.MustNewConstMetric(c.upDesc, prometheus.GaugeValue, up)
+	for name, t := range s.lastSuccessfulTime {
+		ch <- prometheus.MustNewConstMetric(c.lastSuccessfulTimeDesc, prometheus.GaugeValue, float64(t.Unix()), name)
+	}
+	if s.lastErrorReason != "" {
+		ch <- prometheus.MustNewConstMetric(c.lastErrorDesc, prometheus.GaugeValue, 1.0, s.lastErrorReason)
+	}
+}
@@ -0,0 +1,49 @@
+package collector
+
+import (
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/sapcc/netapp-api-exporter/pkg/netapp"
+)
+
+func TestFilerStatus(t *testing.T) {
+	dnsErr := &net.DNSError{Err: "no such host", Name: "filer.example.com"}
+	apiErr := errors.New("volume-get-iter failed")
+	steps := []struct {
+		name   string
+		report func(s *FilerStatus)
+		up     bool
+		reason string
+	}{
+		{"initial", func(s *FilerStatus) {}, false, ""},
+		{"failed check", func(s *FilerStatus) { s.ReportCheck(dnsErr) }, false, netapp.ErrorReasonDNS},
+		{"successful scrape", func(s *FilerStatus) { s.reportScrape("volume", nil) }, false, netapp.ErrorReasonDNS},
+		{"successful check", func(s *FilerStatus) { s.ReportCheck(nil) }, true, ""},
+		{"failed api scrape", func(s *FilerStatus) { s.reportScrape("volume", apiErr) }, true, netapp.ErrorReasonAPI},
+		{"failed dns scrape", func(s *FilerStatus) { s.reportScrape("volume", dnsErr) }, true, netapp.ErrorReasonDNS},
+		{"successful check again", func(s *FilerStatus) { s.ReportCheck(nil) }, true, ""},
+		{"failed check again", func(s *FilerStatus) { s.ReportCheck(netapp.ErrAuthentication) }, false, netapp.ErrorReasonAuth},
+	}
+	s := NewFilerStatus()
+	for _, step := range steps {
+		step.report(s)
+		if s.Up() != step.up || s.lastErrorReason != step.reason {
+			t.Errorf("%s: got up %v and reason %q, want up %v and reason %q",
+				step.name, s.Up(), s.lastErrorReason, step.up, step.reason)
+		}
+	}
+	if _, ok := s.lastSuccessfulTime["volume"]; !ok {
+		t.Errorf("successful scrape of volume collector not recorded")
+	}
+}
+
+func TestFilerStatusSeparate(t *testing.T) {
+	s1, s2 := NewFilerStatus(), NewFilerStatus()
+	s1.ReportCheck(nil)
+	s2.ReportCheck(netapp.ErrAuthentication)
+	if !s1.Up() || s1.lastErrorReason != "" {
+		t.Errorf("status is changed by the check of another status")
+	}
+}
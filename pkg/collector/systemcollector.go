@@ -2,6 +2,8 @@ package collector
 
 import (
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/sapcc/netapp-api-exporter/pkg/netapp"
@@ -9,14 +11,40 @@ import (
 )
 
 type SystemCollector struct {
-	filerName   string
-	versionDesc *prometheus.Desc
-	client      *netapp.Client
+	filerName            string
+	status               *FilerStatus
+	versionDesc          *prometheus.Desc
+	nvramBatteryDesc     *prometheus.Desc
+	cpuBusyDesc          *prometheus.Desc
+	processorBusyDesc    *prometheus.Desc
+	diskBusyDesc         *prometheus.Desc
+	protocolOpsDesc      *prometheus.Desc
+	totalOpsDesc         *prometheus.Desc
+	client               *netapp.Client
+	nodePerfSamples      map[string]*netapp.NodePerf
+	scrapeCounter        prometheus.Counter
+	scrapeFailureCounter prometheus.Counter
+	scrapeDurationGauge  prometheus.Gauge
+	mux                  sync.Mutex
 }
 
-func NewSystemCollector(client *netapp.Client, filerName string) *SystemCollector {
+// nodePerfRates are computed from two consecutive samples of node perf
+// counters.
+type nodePerfRates struct {
+	CPUBusy       float64
+	ProcessorBusy float64
+	DiskBusy      float64
+	NFSOps        float64
+	CIFSOps       float64
+	ISCSIOps      float64
+	FCPOps        float64
+	TotalOps      float64
+}
+
+func NewSystemCollector(client *netapp.Client, filerName string, status *FilerStatus) *SystemCollector {
 	return &SystemCollector{
 		filerName: filerName,
+		status:    status,
 		client:    client,
 		versionDesc: prometheus.NewDesc(
 			"netapp_filer_system_version",
@@ -24,19 +52,109 @@ func NewSystemCollector(client *netapp.Client, filerName string) *SystemCollecto
 			[]string{"full_version", "version"},
 			nil,
 		),
+		nvramBatteryDesc: prometheus.NewDesc(
+			"netapp_node_nvram_battery_ok",
+			"Netapp Node: nvram battery status is ok",
+			[]string{"node", "status"},
+			nil,
+		),
+		cpuBusyDesc: prometheus.NewDesc(
+			"netapp_node_cpu_busy_ratio",
+			"Netapp Node Perf: cpu busy ratio",
+			[]string{"node"},
+			nil,
+		),
+		processorBusyDesc: prometheus.NewDesc(
+			"netapp_node_processor_busy_ratio",
+			"Netapp Node Perf: busy ratio averaged over all processors",
+			[]string{"node"},
+			nil,
+		),
+		diskBusyDesc: prometheus.NewDesc(
+			"netapp_node_disk_busy_ratio",
+			"Netapp Node Perf: busy ratio averaged over all disks owned by the node",
+			[]string{"node"},
+			nil,
+		),
+		protocolOpsDesc: prometheus.NewDesc(
+			"netapp_node_protocol_ops_per_second",
+			"Netapp Node Perf: operations per second by protocol (nfs, cifs, iscsi, fcp)",
+			[]string{"node", "protocol"},
+			nil,
+		),
+		totalOpsDesc: prometheus.NewDesc(
+			"netapp_node_total_ops_per_second",
+			"Netapp Node Perf: total operations per second",
+			[]string{"node"},
+			nil,
+		),
+		scrapeCounter: prometheus.NewCounter(
+			prometheus.CounterOpts{
+				Name: "netapp_system_scrape_total",
+				Help: "number of node and node perf counter fetches from filer",
+			},
+		),
+		scrapeFailureCounter: prometheus.NewCounter(
+			prometheus.CounterOpts{
+				Name: "netapp_system_scrape_failure_total",
+				Help: "number of failures for fetching nodes or node perf counters from filer",
+			},
+		),
+		scrapeDurationGauge: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "netapp_system_scrape_duration_seconds",
+				Help: "duration in seconds of fetching nodes and node perf counters from filer",
+			},
+		),
 	}
 }
 
 func (c *SystemCollector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- c.versionDesc
+	ch <- c.nvramBatteryDesc
+	ch <- c.cpuBusyDesc
+	ch <- c.processorBusyDesc
+	ch <- c.diskBusyDesc
+	ch <- c.protocolOpsDesc
+	ch <- c.totalOpsDesc
+	ch <- c.scrapeCounter.Desc()
+	ch <- c.scrapeFailureCounter.Desc()
+	ch <- c.scrapeDurationGauge.Desc()
 }
 
 func (c *SystemCollector) Collect(ch chan<- prometheus.Metric) {
-	fullVersion, err := c.client.GetSystemVersion()
+	start := time.Now()
+	c.collectNodes(ch)
+	c.collectNodePerf(ch)
+	elapsed := time.Now().Sub(start)
+	c.scrapeCounter.Inc()
+	c.scrapeDurationGauge.Set(elapsed.Seconds())
+	c.scrapeCounter.Collect(ch)
+	c.scrapeFailureCounter.Collect(ch)
+	c.scrapeDurationGauge.Collect(ch)
+}
+
+func (c *SystemCollector) collectNodes(ch chan<- prometheus.Metric) {
+	nodes, err := c.client.ListNodes()
+	c.status.reportScrape("system", err)
 	if err != nil {
-		log.WithError(err).Error("get system version failed")
+		log.WithField("filer", c.filerName).WithError(err).Error("list nodes failed")
+		c.scrapeFailureCounter.Inc()
+		return
+	}
+	for _, node := range nodes {
+		var batteryOk float64
+		if node.NvramBatteryStatus == "battery_ok" {
+			batteryOk = 1.0
+		}
+		ch <- prometheus.MustNewConstMetric(c.nvramBatteryDesc, prometheus.GaugeValue, batteryOk,
+			node.Name, node.NvramBatteryStatus)
+	}
+
+	if len(nodes) == 0 {
 		return
 	}
+	fullVersion := nodes[0].ProductVersion
 	idx := strings.Index(fullVersion, ":")
 	if idx == -1 {
 		log.Warnf("[%s] Failed to extract version from string %q", c.filerName, fullVersion)
@@ -51,3 +169,69 @@ func (c *SystemCollector) Collect(ch chan<- prometheus.Metric) {
 		version,
 	)
 }
+
+func (c *SystemCollector) collectNodePerf(ch chan<- prometheus.Metric) {
+	defer c.mux.Unlock()
+	c.mux.Lock()
+
+	perfs, err := c.client.ListNodePerf()
+	c.status.reportScrape("system", err)
+	if err != nil {
+		log.WithField("filer", c.filerName).WithError(err).Error("fetch node perf failed")
+		c.scrapeFailureCounter.Inc()
+		return
+	}
+	samples := make(map[string]*netapp.NodePerf, len(perfs))
+	for _, p := range perfs {
+		samples[p.Node] = p
+		prev, ok := c.nodePerfSamples[p.Node]
+		if !ok {
+			continue
+		}
+		r, ok := computeNodePerfRates(prev, p)
+		if !ok {
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(c.cpuBusyDesc, prometheus.GaugeValue, r.CPUBusy, p.Node)
+		ch <- prometheus.MustNewConstMetric(c.processorBusyDesc, prometheus.GaugeValue, r.ProcessorBusy, p.Node)
+		ch <- prometheus.MustNewConstMetric(c.diskBusyDesc, prometheus.GaugeValue, r.DiskBusy, p.Node)
+		ch <- prometheus.MustNewConstMetric(c.protocolOpsDesc, prometheus.GaugeValue, r.NFSOps, p.Node, "nfs")
+		ch <- prometheus.MustNewConstMetric(c.protocolOpsDesc, prometheus.GaugeValue, r.CIFSOps, p.Node, "cifs")
+		ch <- prometheus.MustNewConstMetric(c.protocolOpsDesc, prometheus.GaugeValue, r.ISCSIOps, p.Node, "iscsi")
+		ch <- prometheus.MustNewConstMetric(c.protocolOpsDesc, prometheus.GaugeValue, r.FCPOps, p.Node, "fcp")
+		ch <- prometheus.MustNewConstMetric(c.totalOpsDesc, prometheus.GaugeValue, r.TotalOps, p.Node)
+	}
+	c.nodePerfSamples = samples
+}
+
+// computeNodePerfRates returns false if any of the counters has been reset, or
+// if processors or disks have been added or removed since the previous sample.
+func computeNodePerfRates(prev, cur *netapp.NodePerf) (*nodePerfRates, bool) {
+	if prev.ProcessorCount != cur.ProcessorCount || prev.DiskCount != cur.DiskCount {
+		return nil, false
+	}
+	seconds := cur.Timestamp.Sub(prev.Timestamp).Seconds()
+	valid := true
+	rate := func(prev, cur uint64) float64 {
+		v, ok := perfRate(prev, cur, seconds)
+		valid = valid && ok
+		return v
+	}
+	// busy counters divided by their base counters give the busy ratio
+	ratio := func(prev, cur, prevBase, curBase uint64) float64 {
+		v, ok := perfAverage(prev, cur, prevBase, curBase)
+		valid = valid && ok
+		return v
+	}
+	r := &nodePerfRates{
+		CPUBusy:       ratio(prev.CPUBusy, cur.CPUBusy, prev.CPUElapsedTime, cur.CPUElapsedTime),
+		ProcessorBusy: ratio(prev.ProcessorBusy, cur.ProcessorBusy, prev.ProcessorElapsedTime, cur.ProcessorElapsedTime),
+		DiskBusy:      ratio(prev.DiskBusy, cur.DiskBusy, prev.DiskBusyBase, cur.DiskBusyBase),
+		NFSOps:        rate(prev.NFSOps, cur.NFSOps),
+		CIFSOps:       rate(prev.CIFSOps, cur.CIFSOps),
+		ISCSIOps:      rate(prev.ISCSIOps, cur.ISCSIOps),
+		FCPOps:        rate(prev.FCPOps, cur.FCPOps),
+		TotalOps:      rate(prev.TotalOps, cur.TotalOps),
+	}
+	return r, valid
+}
@@ -11,6 +11,7 @@ import (
 
 type VolumeCollector struct {
 	filerName            string
+	status               *FilerStatus
 	client               *netapp.Client
 	volumes              []*netapp.Volume
 	volumeMetrics        []VolumeMetric
@@ -20,16 +21,18 @@ type VolumeCollector struct {
 	scrapeDurationGauge  prometheus.Gauge
 	mux                  sync.Mutex
 	fetchPeriod          time.Duration
+	cancelCh             chan int
 }
 
+var volumeLabels = []string{"aggregate", "node", "vserver", "volume", "volume_type", "volume_state", "project_id", "share_id", "share_name", "share_type", "snapshot_policy"}
+
 type VolumeMetric struct {
 	desc      *prometheus.Desc
 	valueType prometheus.ValueType
 	getterFn  func(volume *netapp.Volume) float64
 }
 
-func NewVolumeCollector(client *netapp.Client, filerName string, fetchPeriod time.Duration) *VolumeCollector {
-	volumeLabels := []string{"aggregate", "node", "vserver", "volume", "volume_type", "volume_state", "project_id", "share_id", "share_name", "share_type", "snapshot_policy"}
+func NewVolumeCollector(client *netapp.Client, filerName string, status *FilerStatus, fetchPeriod time.Duration) *VolumeCollector {
 	volumeMetrics := []VolumeMetric{
 		{
 			desc: prometheus.NewDesc(
@@ -232,6 +235,7 @@ func NewVolumeCollector(client *netapp.Client, filerName string, fetchPeriod tim
 	)
 	c := &VolumeCollector{
 		filerName:            filerName,
+		status:               status,
 		client:               client,
 		fetchPeriod:          fetchPeriod,
 		volumeMetrics:        volumeMetrics,
@@ -239,11 +243,20 @@ func NewVolumeCollector(client *netapp.Client, filerName string, fetchPeriod tim
 		scrapeCounter:        scrapeCounter,
 		scrapeFailureCounter: scrapeFailureCounter,
 		scrapeDurationGauge:  scrapeDurationGauge,
+		cancelCh:             make(chan int),
+	}
+	if fetchPeriod > 0 {
+		go c.PeriodicFetch(c.cancelCh)
 	}
-	go c.PeriodicFetch(nil)
 	return c
 }
 
+// Stop terminates the periodic fetching of volumes. It must be called when the
+// collector is unregistered, and must not be called more than once.
+func (c *VolumeCollector) Stop() {
+	close(c.cancelCh)
+}
+
 func (c *VolumeCollector) Describe(ch chan<- *prometheus.Desc) {
 	for _, m := range c.volumeMetrics {
 		ch <- m.desc
@@ -261,11 +274,9 @@ func (c *VolumeCollector) Collect(ch chan<- prometheus.Metric) {
 	// export metrics
 	log.Debugf("VolumeCollector[%v] Collect() exporting %d volumes", c.filerName, len(c.volumes))
 	for _, volume := range c.volumes {
-		volumeLabels := []string{
-			volume.Aggregate, volume.Node, volume.Vserver, volume.Volume, volume.VolumeType, volume.VolumeState,
-			volume.ProjectID, volume.ShareID, volume.ShareName, volume.ShareType, volume.SnapshotPolicy}
+		labels := volumeLabelValues(volume)
 		for _, m := range c.volumeMetrics {
-			ch <- prometheus.MustNewConstMetric(m.desc, m.valueType, m.getterFn(volume), volumeLabels...)
+			ch <- prometheus.MustNewConstMetric(m.desc, m.valueType, m.getterFn(volume), labels...)
 		}
 	}
 	c.volumeTotalGauge.Collect(ch)
@@ -275,6 +286,25 @@ func (c *VolumeCollector) Collect(ch chan<- prometheus.Metric) {
 	return
 }
 
+// Volumes returns the cached volumes, which are also used by other collectors to
+// label per-volume metrics.
+func (c *VolumeCollector) Volumes() []*netapp.Volume {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	return c.volumes
+}
+
+// Refresh fetches the volumes synchronously and replaces the cached volumes. It
+// is used instead of PeriodicFetch() if the collector is created with a zero
+// fetch period.
+func (c *VolumeCollector) Refresh() {
+	volumes := c.Fetch()
+	c.mux.Lock()
+	c.volumes = volumes
+	c.mux.Unlock()
+	c.volumeTotalGauge.Set(float64(len(volumes)))
+}
+
 func (c *VolumeCollector) PeriodicFetch(cancelCh <-chan int) {
 	var clearTimer *time.Timer
 	startTimer := time.NewTimer(time.Millisecond)
@@ -283,9 +313,13 @@ func (c *VolumeCollector) PeriodicFetch(cancelCh <-chan int) {
 	for {
 		select {
 		case <-cancelCh:
+			startTimer.Stop()
 			fetchTicker.Stop()
-			clearTimer.Stop()
-			break
+			if clearTimer != nil {
+				clearTimer.Stop()
+			}
+			log.Debugf("VolumeCollector[%v] stopped fetching volumes", c.filerName)
+			return
 		case <-fetchTicker.C:
 		case <-startTimer.C:
 			// Fetch immediately without waiting for the first tick
@@ -320,6 +354,7 @@ func (c *VolumeCollector) Fetch() []*netapp.Volume {
 	elapsed := time.Now().Sub(start)
 	c.scrapeCounter.Inc()
 	c.scrapeDurationGauge.Set(elapsed.Seconds())
+	c.status.reportScrape("volume", err)
 	if err != nil {
 		log.WithField("filer", c.filerName).WithError(err).Error("fetch volume failed")
 		c.scrapeFailureCounter.Inc()
@@ -328,3 +363,9 @@ func (c *VolumeCollector) Fetch() []*netapp.Volume {
 	log.Debugf("VolumeCollector[%v] fetch() fetched %d volumes", c.filerName, len(volumes))
 	return volumes
 }
+
+func volumeLabelValues(v *netapp.Volume) []string {
+	return []string{
+		v.Aggregate, v.Node, v.Vserver, v.Volume, v.VolumeType, v.VolumeState,
+		v.ProjectID, v.ShareID, v.ShareName, v.ShareType, v.SnapshotPolicy}
+}
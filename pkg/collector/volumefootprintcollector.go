@@ -0,0 +1,168 @@
+package collector
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sapcc/netapp-api-exporter/pkg/netapp"
+	log "github.com/sirupsen/logrus"
+)
+
+type VolumeFootprintCollector struct {
+	client               *netapp.Client
+	filerName            string
+	status               *FilerStatus
+	volumeCollector      *VolumeCollector
+	footprintMetrics     []VolumeFootprintMetric
+	scrapeCounter        prometheus.Counter
+	scrapeFailureCounter prometheus.Counter
+	scrapeDurationGauge  prometheus.Gauge
+}
+
+type VolumeFootprintMetric struct {
+	desc      *prometheus.Desc
+	valueType prometheus.ValueType
+	getterFn  func(f *netapp.VolumeFootprint) float64
+}
+
+// NewVolumeFootprintCollector returns a collector for the space components of
+// volumes. Like the volume perf metrics, the footprint metrics are labeled with
+// the volume labels from the volume collector's cache.
+func NewVolumeFootprintCollector(client *netapp.Client, filerName string, status *FilerStatus, volumeCollector *VolumeCollector) *VolumeFootprintCollector {
+	footprintMetrics := []VolumeFootprintMetric{
+		{
+			desc:      prometheus.NewDesc("netapp_volume_space_user_data_bytes", "Netapp Volume Space: user data", volumeLabels, nil),
+			valueType: prometheus.GaugeValue,
+			getterFn:  func(f *netapp.VolumeFootprint) float64 { return f.UserData },
+		}, {
+			desc:      prometheus.NewDesc("netapp_volume_space_filesystem_metadata_bytes", "Netapp Volume Space: filesystem metadata", volumeLabels, nil),
+			valueType: prometheus.GaugeValue,
+			getterFn:  func(f *netapp.VolumeFootprint) float64 { return f.FilesystemMetadata },
+		}, {
+			desc:      prometheus.NewDesc("netapp_volume_space_inodes_bytes", "Netapp Volume Space: inodes", volumeLabels, nil),
+			valueType: prometheus.GaugeValue,
+			getterFn:  func(f *netapp.VolumeFootprint) float64 { return f.Inodes },
+		}, {
+			desc:      prometheus.NewDesc("netapp_volume_space_snapshot_reserve_bytes", "Netapp Volume Space: snapshot reserve", volumeLabels, nil),
+			valueType: prometheus.GaugeValue,
+			getterFn:  func(f *netapp.VolumeFootprint) float64 { return f.SnapshotReserve },
+		}, {
+			desc:      prometheus.NewDesc("netapp_volume_space_snapshot_spill_bytes", "Netapp Volume Space: snapshot space exceeding the snapshot reserve", volumeLabels, nil),
+			valueType: prometheus.GaugeValue,
+			getterFn:  func(f *netapp.VolumeFootprint) float64 { return f.SnapshotSpill },
+		}, {
+			desc:      prometheus.NewDesc("netapp_volume_space_dedupe_metadata_bytes", "Netapp Volume Space: deduplication metadata", volumeLabels, nil),
+			valueType: prometheus.GaugeValue,
+			getterFn:  func(f *netapp.VolumeFootprint) float64 { return f.DedupeMetadata },
+		}, {
+			desc:      prometheus.NewDesc("netapp_volume_space_performance_metadata_bytes", "Netapp Volume Space: performance metadata", volumeLabels, nil),
+			valueType: prometheus.GaugeValue,
+			getterFn:  func(f *netapp.VolumeFootprint) float64 { return f.PerformanceMetadata },
+		}, {
+			desc:      prometheus.NewDesc("netapp_volume_space_total_used_bytes", "Netapp Volume Space: total used", volumeLabels, nil),
+			valueType: prometheus.GaugeValue,
+			getterFn:  func(f *netapp.VolumeFootprint) float64 { return f.TotalUsed },
+		}, {
+			desc:      prometheus.NewDesc("netapp_volume_footprint_guarantee_bytes", "Netapp Volume Footprint: space reserved by the volume guarantee", volumeLabels, nil),
+			valueType: prometheus.GaugeValue,
+			getterFn:  func(f *netapp.VolumeFootprint) float64 { return f.GuaranteeFootprint },
+		}, {
+			desc:      prometheus.NewDesc("netapp_volume_footprint_data_bytes", "Netapp Volume Footprint: data blocks", volumeLabels, nil),
+			valueType: prometheus.GaugeValue,
+			getterFn:  func(f *netapp.VolumeFootprint) float64 { return f.BlocksFootprint },
+		}, {
+			desc:      prometheus.NewDesc("netapp_volume_footprint_performance_tier_bytes", "Netapp Volume Footprint: data blocks in the performance tier", volumeLabels, nil),
+			valueType: prometheus.GaugeValue,
+			getterFn:  func(f *netapp.VolumeFootprint) float64 { return f.PerformanceTierFootprint },
+		}, {
+			desc:      prometheus.NewDesc("netapp_volume_footprint_capacity_tier_bytes", "Netapp Volume Footprint: data blocks in the (FabricPool) capacity tier", volumeLabels, nil),
+			valueType: prometheus.GaugeValue,
+			getterFn:  func(f *netapp.VolumeFootprint) float64 { return f.CapacityTierFootprint },
+		}, {
+			desc:      prometheus.NewDesc("netapp_volume_footprint_metadata_bytes", "Netapp Volume Footprint: flexvol metadata", volumeLabels, nil),
+			valueType: prometheus.GaugeValue,
+			getterFn:  func(f *netapp.VolumeFootprint) float64 { return f.MetadataFootprint },
+		}, {
+			desc:      prometheus.NewDesc("netapp_volume_footprint_delayed_free_bytes", "Netapp Volume Footprint: delayed frees", volumeLabels, nil),
+			valueType: prometheus.GaugeValue,
+			getterFn:  func(f *netapp.VolumeFootprint) float64 { return f.DelayedFreeFootprint },
+		}, {
+			desc:      prometheus.NewDesc("netapp_volume_footprint_total_bytes", "Netapp Volume Footprint: total footprint in the aggregate", volumeLabels, nil),
+			valueType: prometheus.GaugeValue,
+			getterFn:  func(f *netapp.VolumeFootprint) float64 { return f.TotalFootprint },
+		},
+	}
+	scrapeDurationGauge := prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "netapp_volume_footprint_scrape_duration_seconds",
+			Help: "duration in seconds of fetching volume footprints from filer",
+		},
+	)
+	scrapeCounter := prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "netapp_volume_footprint_scrape_total",
+			Help: "number of volume footprint fetches from filer",
+		},
+	)
+	scrapeFailureCounter := prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "netapp_volume_footprint_scrape_failure_total",
+			Help: "number of failures for fetching volume footprints from filer",
+		},
+	)
+	return &VolumeFootprintCollector{
+		client:               client,
+		filerName:            filerName,
+		status:               status,
+		volumeCollector:      volumeCollector,
+		footprintMetrics:     footprintMetrics,
+		scrapeCounter:        scrapeCounter,
+		scrapeFailureCounter: scrapeFailureCounter,
+		scrapeDurationGauge:  scrapeDurationGauge,
+	}
+}
+
+func (c *VolumeFootprintCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, m := range c.footprintMetrics {
+		ch <- m.desc
+	}
+	ch <- c.scrapeCounter.Desc()
+	ch <- c.scrapeFailureCounter.Desc()
+	ch <- c.scrapeDurationGauge.Desc()
+}
+
+func (c *VolumeFootprintCollector) Collect(ch chan<- prometheus.Metric) {
+	footprints := make(map[string]*netapp.VolumeFootprint)
+	for _, f := range c.Fetch() {
+		footprints[f.Vserver+"/"+f.Volume] = f
+	}
+
+	for _, volume := range c.volumeCollector.Volumes() {
+		f, ok := footprints[volume.Vserver+"/"+volume.Volume]
+		if !ok {
+			continue
+		}
+		labels := volumeLabelValues(volume)
+		for _, m := range c.footprintMetrics {
+			ch <- prometheus.MustNewConstMetric(m.desc, m.valueType, m.getterFn(f), labels...)
+		}
+	}
+	c.scrapeCounter.Collect(ch)
+	c.scrapeFailureCounter.Collect(ch)
+	c.scrapeDurationGauge.Collect(ch)
+}
+
+func (c *VolumeFootprintCollector) Fetch() []*netapp.VolumeFootprint {
+	start := time.Now()
+	footprints, err := c.client.ListVolumeFootprints()
+	elapsed := time.Now().Sub(start)
+	c.scrapeCounter.Inc()
+	c.scrapeDurationGauge.Set(elapsed.Seconds())
+	c.status.reportScrape("volume-footprint", err)
+	if err != nil {
+		log.WithField("filer", c.filerName).WithError(err).Error("list volume footprints failed")
+		c.scrapeFailureCounter.Inc()
+		return nil
+	}
+	return footprints
+}
@@ -0,0 +1,203 @@
+package collector
+
+import (
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sapcc/netapp-api-exporter/pkg/netapp"
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	volumeMovePhases = map[string]float64{
+		"queued":                1,
+		"initializing":          2,
+		"replicating":           3,
+		"cutover_hard_deferred": 4,
+		"cutover_soft_deferred": 5,
+		"cutover":               6,
+		"finishing":             7,
+		"completed":             8,
+		"failed":                9,
+		"aborted":               10,
+	}
+	volumeMoveStates = map[string]float64{
+		"healthy": 1,
+		"warning": 2,
+		"alert":   3,
+		"failed":  4,
+		"done":    5,
+	}
+)
+
+type VolumeMoveCollector struct {
+	client                  *netapp.Client
+	filerName               string
+	status                  *FilerStatus
+	moveMetrics             []VolumeMoveMetric
+	estimatedCompletionDesc *prometheus.Desc
+	scrapeCounter           prometheus.Counter
+	scrapeFailureCounter    prometheus.Counter
+	scrapeDurationGauge     prometheus.Gauge
+}
+
+type VolumeMoveMetric struct {
+	desc      *prometheus.Desc
+	valueType prometheus.ValueType
+	getterFn  func(m *netapp.VolumeMove) float64
+}
+
+var volumeMoveLabels = []string{"vserver", "volume", "source_aggregate", "destination_aggregate", "source_node", "destination_node"}
+
+func NewVolumeMoveCollector(client *netapp.Client, filerName string, status *FilerStatus) *VolumeMoveCollector {
+	moveMetrics := []VolumeMoveMetric{
+		{
+			desc: prometheus.NewDesc(
+				"netapp_volume_move_phase",
+				"Netapp Volume Move: phase (1: queued; 2: initializing; 3: replicating; 4: cutover_hard_deferred; 5: cutover_soft_deferred; 6: cutover; 7: finishing; 8: completed; 9: failed; 10: aborted; 0: other)",
+				volumeMoveLabels,
+				nil),
+			valueType: prometheus.GaugeValue,
+			getterFn:  func(m *netapp.VolumeMove) float64 { return volumeMovePhases[m.Phase] },
+		}, {
+			desc: prometheus.NewDesc(
+				"netapp_volume_move_state",
+				"Netapp Volume Move: state (1: healthy; 2: warning; 3: alert; 4: failed; 5: done; 0: other)",
+				volumeMoveLabels,
+				nil),
+			valueType: prometheus.GaugeValue,
+			getterFn:  func(m *netapp.VolumeMove) float64 { return volumeMoveStates[m.State] },
+		}, {
+			desc:      prometheus.NewDesc("netapp_volume_move_percent_complete", "Netapp Volume Move: percentage of the move completed", volumeMoveLabels, nil),
+			valueType: prometheus.GaugeValue,
+			getterFn:  func(m *netapp.VolumeMove) float64 { return m.PercentComplete },
+		}, {
+			desc:      prometheus.NewDesc("netapp_volume_move_sent_bytes", "Netapp Volume Move: bytes sent to the destination aggregate", volumeMoveLabels, nil),
+			valueType: prometheus.GaugeValue,
+			getterFn:  func(m *netapp.VolumeMove) float64 { return m.BytesSent },
+		}, {
+			desc:      prometheus.NewDesc("netapp_volume_move_remaining_bytes", "Netapp Volume Move: bytes remaining to be sent to the destination aggregate", volumeMoveLabels, nil),
+			valueType: prometheus.GaugeValue,
+			getterFn:  func(m *netapp.VolumeMove) float64 { return m.BytesRemaining },
+		}, {
+			desc:      prometheus.NewDesc("netapp_volume_move_cutover_attempts", "Netapp Volume Move: number of cutover attempts made", volumeMoveLabels, nil),
+			valueType: prometheus.GaugeValue,
+			getterFn:  func(m *netapp.VolumeMove) float64 { return m.CutoverAttemptedCount },
+		}, {
+			desc:      prometheus.NewDesc("netapp_volume_move_cutover_attempts_max", "Netapp Volume Move: configured maximum number of cutover attempts", volumeMoveLabels, nil),
+			valueType: prometheus.GaugeValue,
+			getterFn:  func(m *netapp.VolumeMove) float64 { return m.CutoverAttempts },
+		},
+	}
+	scrapeDurationGauge := prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "netapp_volume_move_scrape_duration_seconds",
+			Help: "duration in seconds of fetching volume moves from filer",
+		},
+	)
+	scrapeCounter := prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "netapp_volume_move_scrape_total",
+			Help: "number of volume move fetches from filer",
+		},
+	)
+	scrapeFailureCounter := prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "netapp_volume_move_scrape_failure_total",
+			Help: "number of failures for fetching volume moves from filer",
+		},
+	)
+	return &VolumeMoveCollector{
+		client:      client,
+		filerName:   filerName,
+		status:      status,
+		moveMetrics: moveMetrics,
+		estimatedCompletionDesc: prometheus.NewDesc(
+			"netapp_volume_move_estimated_completion_timestamp_seconds",
+			"Netapp Volume Move: estimated time of completion",
+			volumeMoveLabels,
+			nil),
+		scrapeDurationGauge:  scrapeDurationGauge,
+		scrapeCounter:        scrapeCounter,
+		scrapeFailureCounter: scrapeFailureCounter,
+	}
+}
+
+func (c *VolumeMoveCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, m := range c.moveMetrics {
+		ch <- m.desc
+	}
+	ch <- c.estimatedCompletionDesc
+	ch <- c.scrapeCounter.Desc()
+	ch <- c.scrapeFailureCounter.Desc()
+	ch <- c.scrapeDurationGauge.Desc()
+}
+
+func (c *VolumeMoveCollector) Collect(ch chan<- prometheus.Metric) {
+	moves := c.Fetch()
+
+	// the filer may keep finished moves of the same volume between the same
+	// aggregates, only the running or else the latest one is exported
+	latest := make(map[string]*netapp.VolumeMove, len(moves))
+	var keys []string
+	for _, m := range moves {
+		key := strings.Join(volumeMoveLabelValues(m), "/")
+		if prev, ok := latest[key]; !ok {
+			keys = append(keys, key)
+		} else if !preferVolumeMove(m, prev) {
+			continue
+		}
+		latest[key] = m
+	}
+	for _, key := range keys {
+		m := latest[key]
+		labels := volumeMoveLabelValues(m)
+		for _, metric := range c.moveMetrics {
+			ch <- prometheus.MustNewConstMetric(metric.desc, metric.valueType, metric.getterFn(m), labels...)
+		}
+		// finished moves have no estimated completion time
+		if m.EstimatedCompletionTime > 0 {
+			ch <- prometheus.MustNewConstMetric(c.estimatedCompletionDesc, prometheus.GaugeValue, m.EstimatedCompletionTime, labels...)
+		}
+	}
+	c.scrapeCounter.Collect(ch)
+	c.scrapeFailureCounter.Collect(ch)
+	c.scrapeDurationGauge.Collect(ch)
+}
+
+func (c *VolumeMoveCollector) Fetch() []*netapp.VolumeMove {
+	start := time.Now()
+	moves, err := c.client.ListVolumeMoves()
+	elapsed := time.Now().Sub(start)
+	c.scrapeCounter.Inc()
+	c.scrapeDurationGauge.Set(elapsed.Seconds())
+	c.status.reportScrape("volume-move", err)
+	if err != nil {
+		log.WithField("filer", c.filerName).WithError(err).Error("list volume moves failed")
+		c.scrapeFailureCounter.Inc()
+		return nil
+	}
+	return moves
+}
+
+func volumeMoveLabelValues(m *netapp.VolumeMove) []string {
+	return []string{m.Vserver, m.Volume, m.SourceAggregate, m.DestinationAggregate, m.SourceNode, m.DestinationNode}
+}
+
+// preferVolumeMove reports whether the move m is exported instead of prev. A
+// running move is preferred to finished ones, otherwise the move started last.
+func preferVolumeMove(m, prev *netapp.VolumeMove) bool {
+	if running, prevRunning := isVolumeMoveRunning(m), isVolumeMoveRunning(prev); running != prevRunning {
+		return running
+	}
+	return m.StartTime > prev.StartTime
+}
+
+func isVolumeMoveRunning(m *netapp.VolumeMove) bool {
+	switch m.Phase {
+	case "completed", "failed", "aborted":
+		return false
+	}
+	return true
+}
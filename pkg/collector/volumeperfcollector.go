@@ -0,0 +1,213 @@
+package collector
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sapcc/netapp-api-exporter/pkg/netapp"
+	log "github.com/sirupsen/logrus"
+)
+
+type VolumePerfCollector struct {
+	filerName            string
+	status               *FilerStatus
+	client               *netapp.Client
+	volumeCollector      *VolumeCollector
+	samples              map[string]*netapp.VolumePerf
+	perfMetrics          []VolumePerfMetric
+	scrapeCounter        prometheus.Counter
+	scrapeFailureCounter prometheus.Counter
+	scrapeDurationGauge  prometheus.Gauge
+	mux                  sync.Mutex
+}
+
+type VolumePerfMetric struct {
+	desc      *prometheus.Desc
+	valueType prometheus.ValueType
+	getterFn  func(r *volumePerfRates) float64
+}
+
+// volumePerfRates are computed from two consecutive samples of volume perf
+// counters. Latencies are in seconds.
+type volumePerfRates struct {
+	ReadOps         float64
+	WriteOps        float64
+	OtherOps        float64
+	TotalOps        float64
+	ReadThroughput  float64
+	WriteThroughput float64
+	ReadLatency     float64
+	WriteLatency    float64
+	OtherLatency    float64
+	AvgLatency      float64
+}
+
+// NewVolumePerfCollector returns a collector which exports volume perf metrics.
+// The labels of the volumes are taken from the volume collector's cache.
+func NewVolumePerfCollector(client *netapp.Client, filerName string, status *FilerStatus, volumeCollector *VolumeCollector) *VolumePerfCollector {
+	perfMetrics := []VolumePerfMetric{
+		{
+			desc:      prometheus.NewDesc("netapp_volume_read_ops_per_second", "Netapp Volume Perf: read operations per second", volumeLabels, nil),
+			valueType: prometheus.GaugeValue,
+			getterFn:  func(r *volumePerfRates) float64 { return r.ReadOps },
+		}, {
+			desc:      prometheus.NewDesc("netapp_volume_write_ops_per_second", "Netapp Volume Perf: write operations per second", volumeLabels, nil),
+			valueType: prometheus.GaugeValue,
+			getterFn:  func(r *volumePerfRates) float64 { return r.WriteOps },
+		}, {
+			desc:      prometheus.NewDesc("netapp_volume_other_ops_per_second", "Netapp Volume Perf: other operations per second", volumeLabels, nil),
+			valueType: prometheus.GaugeValue,
+			getterFn:  func(r *volumePerfRates) float64 { return r.OtherOps },
+		}, {
+			desc:      prometheus.NewDesc("netapp_volume_total_ops_per_second", "Netapp Volume Perf: total operations per second", volumeLabels, nil),
+			valueType: prometheus.GaugeValue,
+			getterFn:  func(r *volumePerfRates) float64 { return r.TotalOps },
+		}, {
+			desc:      prometheus.NewDesc("netapp_volume_read_bytes_per_second", "Netapp Volume Perf: bytes read per second", volumeLabels, nil),
+			valueType: prometheus.GaugeValue,
+			getterFn:  func(r *volumePerfRates) float64 { return r.ReadThroughput },
+		}, {
+			desc:      prometheus.NewDesc("netapp_volume_write_bytes_per_second", "Netapp Volume Perf: bytes written per second", volumeLabels, nil),
+			valueType: prometheus.GaugeValue,
+			getterFn:  func(r *volumePerfRates) float64 { return r.WriteThroughput },
+		}, {
+			desc:      prometheus.NewDesc("netapp_volume_read_latency_seconds", "Netapp Volume Perf: average latency of read operations", volumeLabels, nil),
+			valueType: prometheus.GaugeValue,
+			getterFn:  func(r *volumePerfRates) float64 { return r.ReadLatency },
+		}, {
+			desc:      prometheus.NewDesc("netapp_volume_write_latency_seconds", "Netapp Volume Perf: average latency of write operations", volumeLabels, nil),
+			valueType: prometheus.GaugeValue,
+			getterFn:  func(r *volumePerfRates) float64 { return r.WriteLatency },
+		}, {
+			desc:      prometheus.NewDesc("netapp_volume_other_latency_seconds", "Netapp Volume Perf: average latency of other operations", volumeLabels, nil),
+			valueType: prometheus.GaugeValue,
+			getterFn:  func(r *volumePerfRates) float64 { return r.OtherLatency },
+		}, {
+			desc:      prometheus.NewDesc("netapp_volume_average_latency_seconds", "Netapp Volume Perf: average latency of all operations", volumeLabels, nil),
+			valueType: prometheus.GaugeValue,
+			getterFn:  func(r *volumePerfRates) float64 { return r.AvgLatency },
+		},
+	}
+	scrapeDurationGauge := prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "netapp_volume_perf_scrape_duration_seconds",
+			Help: "duration in seconds of fetching volume perf counters from filer",
+		},
+	)
+	scrapeCounter := prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "netapp_volume_perf_scrape_total",
+			Help: "number of volume perf counter fetches from filer",
+		},
+	)
+	scrapeFailureCounter := prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "netapp_volume_perf_scrape_failure_total",
+			Help: "number of failures for fetching volume perf counters from filer",
+		},
+	)
+	return &VolumePerfCollector{
+		filerName:            filerName,
+		status:               status,
+		client:               client,
+		volumeCollector:      volumeCollector,
+		perfMetrics:          perfMetrics,
+		scrapeCounter:        scrapeCounter,
+		scrapeFailureCounter: scrapeFailureCounter,
+		scrapeDurationGauge:  scrapeDurationGauge,
+	}
+}
+
+func (c *VolumePerfCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, m := range c.perfMetrics {
+		ch <- m.desc
+	}
+	ch <- c.scrapeCounter.Desc()
+	ch <- c.scrapeFailureCounter.Desc()
+	ch <- c.scrapeDurationGauge.Desc()
+}
+
+func (c *VolumePerfCollector) Collect(ch chan<- prometheus.Metric) {
+	defer c.mux.Unlock()
+	c.mux.Lock()
+
+	// Rates are computed against the previous sample of each volume. Samples
+	// are only replaced on successful fetches, so that a failed fetch results
+	// in rates over a longer interval instead of gaps.
+	perfs := c.Fetch()
+	rates := make(map[string]*volumePerfRates)
+	if len(perfs) > 0 {
+		samples := make(map[string]*netapp.VolumePerf, len(perfs))
+		for _, p := range perfs {
+			key := p.Vserver + "/" + p.Volume
+			samples[key] = p
+			if prev, ok := c.samples[key]; ok {
+				if r, ok := computeVolumePerfRates(prev, p); ok {
+					rates[key] = r
+				}
+			}
+		}
+		c.samples = samples
+	}
+
+	for _, volume := range c.volumeCollector.Volumes() {
+		r, ok := rates[volume.Vserver+"/"+volume.Volume]
+		if !ok {
+			continue
+		}
+		labels := volumeLabelValues(volume)
+		for _, m := range c.perfMetrics {
+			ch <- prometheus.MustNewConstMetric(m.desc, m.valueType, m.getterFn(r), labels...)
+		}
+	}
+	c.scrapeCounter.Collect(ch)
+	c.scrapeFailureCounter.Collect(ch)
+	c.scrapeDurationGauge.Collect(ch)
+}
+
+func (c *VolumePerfCollector) Fetch() []*netapp.VolumePerf {
+	start := time.Now()
+	perfs, err := c.client.ListVolumePerf()
+	elapsed := time.Now().Sub(start)
+	c.scrapeCounter.Inc()
+	c.scrapeDurationGauge.Set(elapsed.Seconds())
+	c.status.reportScrape("volume-perf", err)
+	if err != nil {
+		log.WithField("filer", c.filerName).WithError(err).Error("fetch volume perf failed")
+		c.scrapeFailureCounter.Inc()
+		return nil
+	}
+	return perfs
+}
+
+// computeVolumePerfRates returns false if any of the counters has been reset
+// since the previous sample.
+func computeVolumePerfRates(prev, cur *netapp.VolumePerf) (*volumePerfRates, bool) {
+	seconds := cur.Timestamp.Sub(prev.Timestamp).Seconds()
+	valid := true
+	rate := func(prev, cur uint64) float64 {
+		v, ok := perfRate(prev, cur, seconds)
+		valid = valid && ok
+		return v
+	}
+	// latency counters are in microseconds
+	latency := func(prev, cur, prevOps, curOps uint64) float64 {
+		v, ok := perfAverage(prev, cur, prevOps, curOps)
+		valid = valid && ok
+		return v / 1e6
+	}
+	r := &volumePerfRates{
+		ReadOps:         rate(prev.ReadOps, cur.ReadOps),
+		WriteOps:        rate(prev.WriteOps, cur.WriteOps),
+		OtherOps:        rate(prev.OtherOps, cur.OtherOps),
+		TotalOps:        rate(prev.TotalOps, cur.TotalOps),
+		ReadThroughput:  rate(prev.ReadData, cur.ReadData),
+		WriteThroughput: rate(prev.WriteData, cur.WriteData),
+		ReadLatency:     latency(prev.ReadLatency, cur.ReadLatency, prev.ReadOps, cur.ReadOps),
+		WriteLatency:    latency(prev.WriteLatency, cur.WriteLatency, prev.WriteOps, cur.WriteOps),
+		OtherLatency:    latency(prev.OtherLatency, cur.OtherLatency, prev.OtherOps, cur.OtherOps),
+		AvgLatency:      latency(prev.AvgLatency, cur.AvgLatency, prev.TotalOps, cur.TotalOps),
+	}
+	return r, valid
+}
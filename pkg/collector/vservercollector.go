@@ -0,0 +1,144 @@
+package collector
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sapcc/netapp-api-exporter/pkg/netapp"
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	vserverStates = map[string]float64{
+		"running":      1,
+		"stopped":      2,
+		"starting":     3,
+		"stopping":     4,
+		"initializing": 5,
+		"deleting":     6,
+	}
+	vserverOperationalStates = map[string]float64{
+		"running": 1,
+		"stopped": 2,
+	}
+)
+
+type VserverCollector struct {
+	client               *netapp.Client
+	filerName            string
+	status               *FilerStatus
+	vserverMetrics       []VserverMetric
+	vserverInfoDesc      *prometheus.Desc
+	scrapeCounter        prometheus.Counter
+	scrapeFailureCounter prometheus.Counter
+	scrapeDurationGauge  prometheus.Gauge
+}
+
+type VserverMetric struct {
+	desc      *prometheus.Desc
+	valueType prometheus.ValueType
+	getterFn  func(v *netapp.Vserver) float64
+}
+
+func NewVserverCollector(client *netapp.Client, filerName string, status *FilerStatus) *VserverCollector {
+	vserverLabels := []string{"vserver"}
+	vserverMetrics := []VserverMetric{
+		{
+			desc: prometheus.NewDesc(
+				"netapp_vserver_state",
+				"Netapp Vserver: state (1: running; 2: stopped; 3: starting; 4: stopping; 5: initializing; 6: deleting; 0: other)",
+				vserverLabels,
+				nil),
+			valueType: prometheus.GaugeValue,
+			getterFn:  func(v *netapp.Vserver) float64 { return vserverStates[v.State] },
+		}, {
+			desc: prometheus.NewDesc(
+				"netapp_vserver_operational_state",
+				"Netapp Vserver: operational state (1: running; 2: stopped; 0: other)",
+				vserverLabels,
+				nil),
+			valueType: prometheus.GaugeValue,
+			getterFn:  func(v *netapp.Vserver) float64 { return vserverOperationalStates[v.OperationalState] },
+		}, {
+			desc: prometheus.NewDesc(
+				"netapp_vserver_aggregates",
+				"Netapp Vserver: number of aggregates assigned to the vserver",
+				vserverLabels,
+				nil),
+			valueType: prometheus.GaugeValue,
+			getterFn:  func(v *netapp.Vserver) float64 { return v.AggregateCount },
+		},
+	}
+	scrapeDurationGauge := prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "netapp_vserver_scrape_duration_seconds",
+			Help: "duration in seconds of fetching vservers from filer",
+		},
+	)
+	scrapeCounter := prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "netapp_vserver_scrape_total",
+			Help: "number of vserver fetches from filer",
+		},
+	)
+	scrapeFailureCounter := prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "netapp_vserver_scrape_failure_total",
+			Help: "number of failures for fetching vservers from filer",
+		},
+	)
+	return &VserverCollector{
+		client:         client,
+		filerName:      filerName,
+		status:         status,
+		vserverMetrics: vserverMetrics,
+		vserverInfoDesc: prometheus.NewDesc(
+			"netapp_vserver_info",
+			"Netapp Vserver: info about the vserver in labels",
+			append(vserverLabels, "subtype", "root_volume", "ipspace", "allowed_protocols"),
+			nil),
+		scrapeDurationGauge:  scrapeDurationGauge,
+		scrapeCounter:        scrapeCounter,
+		scrapeFailureCounter: scrapeFailureCounter,
+	}
+}
+
+func (c *VserverCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, m := range c.vserverMetrics {
+		ch <- m.desc
+	}
+	ch <- c.vserverInfoDesc
+	ch <- c.scrapeCounter.Desc()
+	ch <- c.scrapeFailureCounter.Desc()
+	ch <- c.scrapeDurationGauge.Desc()
+}
+
+func (c *VserverCollector) Collect(ch chan<- prometheus.Metric) {
+	vservers := c.Fetch()
+
+	for _, v := range vservers {
+		for _, m := range c.vserverMetrics {
+			ch <- prometheus.MustNewConstMetric(m.desc, m.valueType, m.getterFn(v), v.Name)
+		}
+		ch <- prometheus.MustNewConstMetric(c.vserverInfoDesc, prometheus.GaugeValue, 1.0,
+			v.Name, v.Subtype, v.RootVolume, v.IPSpace, v.AllowedProtocols)
+	}
+	c.scrapeCounter.Collect(ch)
+	c.scrapeFailureCounter.Collect(ch)
+	c.scrapeDurationGauge.Collect(ch)
+}
+
+func (c *VserverCollector) Fetch() []*netapp.Vserver {
+	start := time.Now()
+	vservers, err := c.client.ListVservers()
+	elapsed := time.Now().Sub(start)
+	c.scrapeCounter.Inc()
+	c.scrapeDurationGauge.Set(elapsed.Seconds())
+	c.status.reportScrape("vserver", err)
+	if err != nil {
+		log.WithField("filer", c.filerName).WithError(err).Error("list vservers failed")
+		c.scrapeFailureCounter.Inc()
+		return nil
+	}
+	return vservers
+}
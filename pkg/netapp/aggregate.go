@@ -1,6 +1,7 @@
 package netapp
 
 import (
+	"encoding/xml"
 	"strconv"
 
 	n "github.com/pepabo/go-netapp/netapp"
@@ -18,8 +19,48 @@ type Aggregate struct {
 	PhysicalUsedPercent float64
 	IsEncrypted         bool
 	State               string
+	// space breakdown from aggr-space-get-iter, which is only set if
+	// HasSpaceBreakdown is true
+	HasSpaceBreakdown               bool
+	VolumeFootprints                float64
+	AggregateMetadata               float64
+	SnapshotReserve                 float64
+	SnapshotReserveUnusable         float64
+	PerformanceTierInactiveUserData float64
+	SisSpaceSaved                   float64
+	DataCompactionSpaceSaved        float64
 }
 
+// aggrSpaceGetIterRequest is used instead of AggregateSpace.List(), because
+// the go-netapp response type lacks the next-tag and the efficiency attributes.
+type aggrSpaceGetIterRequest struct {
+	n.Base
+	Params struct {
+		XMLName xml.Name
+		*n.AggrSpaceOptions
+	}
+}
+
+type aggrSpaceGetIterResponse struct {
+	XMLName xml.Name `xml:"netapp"`
+	Results struct {
+		n.ResultBase
+		AttributesList struct {
+			SpaceInformation []aggrSpaceInfo `xml:"space-information"`
+		} `xml:"attributes-list"`
+		NextTag string `xml:"next-tag"`
+	} `xml:"results"`
+}
+
+type aggrSpaceInfo struct {
+	n.AggrSpaceInfo
+	PerformanceTierInactiveUserData string `xml:"performance-tier-inactive-user-data"`
+	SisSpaceSaved                   string `xml:"sis-space-saved"`
+	DataCompactionSpaceSaved        string `xml:"data-compaction-space-saved"`
+}
+
+// ListAggregates returns the aggregates without their space breakdown, which
+// is added by AddAggregateSpace.
 func (c *Client) ListAggregates() (aggregates []*Aggregate, err error) {
 	aggrInfos, err := c.listAggregates()
 	if err != nil {
@@ -31,6 +72,24 @@ func (c *Client) ListAggregates() (aggregates []*Aggregate, err error) {
 	return
 }
 
+// AddAggregateSpace adds the space breakdown to the aggregates.
+func (c *Client) AddAggregateSpace(aggregates []*Aggregate) error {
+	spaceInfos, err := c.listAggregateSpace()
+	if err != nil {
+		return err
+	}
+	spaces := make(map[string]aggrSpaceInfo, len(spaceInfos))
+	for _, space := range spaceInfos {
+		spaces[space.Aggregate] = space
+	}
+	for _, aggregate := range aggregates {
+		if space, ok := spaces[aggregate.Name]; ok {
+			parseAggregateSpace(aggregate, space)
+		}
+	}
+	return nil
+}
+
 func (c *Client) listAggregates() (res []n.AggrInfo, err error) {
 	opts := newAggrOpts(false)
 	pageHandler := func(r n.AggrListPagesResponse) bool {
@@ -45,6 +104,30 @@ func (c *Client) listAggregates() (res []n.AggrInfo, err error) {
 	return
 }
 
+func (c *Client) listAggregateSpace() (res []aggrSpaceInfo, err error) {
+	opts := &n.AggrSpaceOptions{MaxRecords: 100}
+	for {
+		req := &aggrSpaceGetIterRequest{Base: c.AggregateSpace.Base}
+		req.Params.XMLName = xml.Name{Local: "aggr-space-get-iter"}
+		req.Params.AggrSpaceOptions = opts
+		resp := aggrSpaceGetIterResponse{}
+		if err = c.doRequest(req, &resp); err != nil {
+			return nil, err
+		}
+		if err = checkResult(&resp.Results.ResultBase); err != nil {
+			return nil, err
+		}
+		res = append(res, resp.Results.AttributesList.SpaceInformation...)
+		if resp.Results.NextTag == "" {
+			return
+		}
+		opts = &n.AggrSpaceOptions{
+			MaxRecords: opts.MaxRecords,
+			Tag:        resp.Results.NextTag,
+		}
+	}
+}
+
 func newAggrOpts(isRootAggregate bool) *n.AggrOptions {
 	return &n.AggrOptions{
 		Query: &n.AggrInfo{
@@ -80,3 +163,21 @@ func parseAggregate(aggrInfo n.AggrInfo) *Aggregate {
 		State:               aggrInfo.AggrRaidAttributes.State,
 	}
 }
+
+func parseAggregateSpace(aggregate *Aggregate, space aggrSpaceInfo) {
+	volumeFootprints, _ := strconv.ParseFloat(space.VolumeFootprints, 64)
+	aggregateMetadata, _ := strconv.ParseFloat(space.AggregateMetadata, 64)
+	snapshotReserve, _ := strconv.ParseFloat(space.SnapSizeTotal, 64)
+	snapshotReserveUnusable, _ := strconv.ParseFloat(space.SnapshotReserveUnusable, 64)
+	inactiveUserData, _ := strconv.ParseFloat(space.PerformanceTierInactiveUserData, 64)
+	sisSpaceSaved, _ := strconv.ParseFloat(space.SisSpaceSaved, 64)
+	dataCompactionSpaceSaved, _ := strconv.ParseFloat(space.DataCompactionSpaceSaved, 64)
+	aggregate.HasSpaceBreakdown = true
+	aggregate.VolumeFootprints = volumeFootprints
+	aggregate.AggregateMetadata = aggregateMetadata
+	aggregate.SnapshotReserve = snapshotReserve
+	aggregate.SnapshotReserveUnusable = snapshotReserveUnusable
+	aggregate.PerformanceTierInactiveUserData = inactiveUserData
+	aggregate.SisSpaceSaved = sisSpaceSaved
+	aggregate.DataCompactionSpaceSaved = dataCompactionSpaceSaved
+}
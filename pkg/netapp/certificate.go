@@ -0,0 +1,43 @@
+package netapp
+
+import (
+	n "github.com/pepabo/go-netapp/netapp"
+)
+
+type Certificate struct {
+	Vserver        string
+	Type           string
+	CommonName     string
+	SerialNumber   string
+	ExpirationTime float64
+}
+
+func (c *Client) ListCertificates() (certificates []*Certificate, err error) {
+	opts := &n.CertificateOptions{
+		MaxRecords: 500,
+	}
+	pageHandler := func(r n.CertificatePagesResponse) bool {
+		if r.Error != nil {
+			err = r.Error
+			return false
+		}
+		if err = checkResult(&r.Response.Results.ResultBase); err != nil {
+			return false
+		}
+		for _, cert := range r.Response.Results.AttributesList {
+			certificates = append(certificates, &Certificate{
+				Vserver:        cert.Vserver,
+				Type:           cert.Type,
+				CommonName:     cert.CommonName,
+				SerialNumber:   cert.SerialNumber,
+				ExpirationTime: float64(cert.ExpirationDate),
+			})
+		}
+		return true
+	}
+	c.Certificate.CertificateGetAll(opts, pageHandler)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
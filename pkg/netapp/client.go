@@ -1,63 +1,118 @@
 package netapp
 
 import (
-	"bytes"
-	"context"
 	"crypto/tls"
-	"encoding/xml"
+	"crypto/x509"
 	"fmt"
+	"io/ioutil"
 	"net/http"
+	"reflect"
 	"time"
+	"unsafe"
 
 	n "github.com/pepabo/go-netapp/netapp"
 )
 
 type Client struct {
 	*n.Client
-	httpClient        *http.Client
-	basicAuthUser     string
-	basicAuthPassword string
 }
 
-func NewClient(host, username, password, version string) (*Client, error) {
+// TLSOptions configure the verification of the filer's certificate and the
+// client certificate, which can be used instead of username and password.
+// The certificate is verified against ServerName if set, and against the host
+// otherwise.
+type TLSOptions struct {
+	Verify     bool
+	CAFile     string
+	CertFile   string
+	KeyFile    string
+	ServerName string
+}
+
+// NewClient returns a client for the filer. go-netapp's own transport cannot
+// be configured with a server name, so its http client is replaced with one
+// using our tls config, and all requests are sent through the same transport.
+func NewClient(host, username, password, version string, tlsOptions TLSOptions) (*Client, error) {
 	baseUrl := fmt.Sprintf("https://%s", host)
 	options := &n.ClientOptions{
 		BasicAuthUser:     username,
 		BasicAuthPassword: password,
-		SSLVerify:         false,
 		Timeout:           30 * time.Second,
 	}
+	tlsConfig, err := newTLSConfig(tlsOptions)
+	if err != nil {
+		return nil, err
+	}
 	httpClient := &http.Client{
 		Timeout: options.Timeout,
 		Transport: &http.Transport{
-			TLSClientConfig: &tls.Config{
-				InsecureSkipVerify: !options.SSLVerify,
-			},
+			TLSClientConfig: tlsConfig,
 		},
 	}
 	c, err := n.NewClient(baseUrl, version, options)
 	if err != nil {
 		return nil, err
 	}
-	return &Client{c, httpClient, username, password}, nil
+	if err := setHTTPClient(c, httpClient); err != nil {
+		return nil, err
+	}
+	return &Client{c}, nil
 }
 
-// Do request with internal http client. Useful to do quick checks.
-func (c *Client) Do(method string, body interface{}) (*http.Response, error) {
-	u, _ := c.BaseURL.Parse("/servlets/netapp.servlets.admin.XMLrequest_filer")
-	buf, err := xml.MarshalIndent(body, "", "  ")
-	if err != nil {
-		return nil, err
+// setHTTPClient replaces the unexported http client of the go-netapp client.
+func setHTTPClient(c *n.Client, httpClient *http.Client) error {
+	f := reflect.ValueOf(c).Elem().FieldByName("client")
+	if !f.IsValid() || f.Type() != reflect.TypeOf(httpClient) {
+		return fmt.Errorf("unable to set http client of go-netapp client")
 	}
-	req, err := http.NewRequest(method, u.String(), bytes.NewBuffer(buf))
+	reflect.NewAt(f.Type(), unsafe.Pointer(f.UnsafeAddr())).Elem().Set(reflect.ValueOf(httpClient))
+	return nil
+}
+
+func newTLSConfig(o TLSOptions) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: !o.Verify,
+		ServerName:         o.ServerName,
+	}
+	if o.CAFile != "" {
+		b, err := ioutil.ReadFile(o.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("unable to load CA cert %s: %s", o.CAFile, err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(b) {
+			return nil, fmt.Errorf("unable to use CA cert %s", o.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if o.CertFile != "" || o.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(o.CertFile, o.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("unable to load client cert %s and key %s: %s", o.CertFile, o.KeyFile, err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
+
+// doRequest sends a ZAPI request through the go-netapp client and decodes the
+// response into v. It is used for api calls which are missing in the go-netapp
+// library, or whose response types lack attributes we need.
+func (c *Client) doRequest(body interface{}, v interface{}) error {
+	req, err := c.Client.NewRequest("POST", body)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	if body != nil {
-		req.Header.Set("Content-Type", "text/xml")
+	_, err = c.Client.Do(req, v)
+	return err
+}
+
+// checkResult returns an error if the api call has not passed. The http status
+// is 200 even if the call itself failed.
+func checkResult(r n.Result) error {
+	if r.Passed() {
+		return nil
 	}
-	req.SetBasicAuth(c.basicAuthUser, c.basicAuthPassword)
-	ctx, cncl := context.WithTimeout(context.Background(), c.ResponseTimeout)
-	defer cncl()
-	return c.httpClient.Do(req.WithContext(ctx))
+	res := r.Result()
+	return fmt.Errorf("api request failed with errno %d: %s", res.ErrorNo, res.Reason)
 }
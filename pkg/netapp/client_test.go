@@ -0,0 +1,68 @@
+package netapp
+
+import (
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// The certificate of httptest servers is valid for example.com and the
+// loopback addresses, but not for localhost.
+func TestClientServerName(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/xml")
+		w.Write([]byte(`<netapp version="1.7"><results status="passed"><num-records>0</num-records></results></netapp>`))
+	}))
+	defer srv.Close()
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	if err := ioutil.WriteFile(caFile, caPEM, 0600); err != nil {
+		t.Fatal(err)
+	}
+	host := strings.Replace(strings.TrimPrefix(srv.URL, "https://"), "127.0.0.1", "localhost", 1)
+
+	tests := []struct {
+		name       string
+		serverName string
+		reason     string
+	}{
+		{"server name", "example.com", ""},
+		{"host", "", ErrorReasonTLS},
+	}
+	for _, tt := range tests {
+		c, err := NewClient(host, "user", "password", "1.7", TLSOptions{
+			Verify:     true,
+			CAFile:     caFile,
+			ServerName: tt.serverName,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		// requests of go-netapp and our own requests use the same transport
+		_, vendorErr := c.ListLuns()
+		checkErr := c.CheckCluster()
+		for _, err := range []error{vendorErr, checkErr} {
+			reason := ""
+			if err != nil {
+				reason = ErrorReason(err)
+			}
+			if reason != tt.reason {
+				t.Errorf("%s: got error %v, want reason %q", tt.name, err, tt.reason)
+			}
+		}
+	}
+}
+
+func TestNewClientInvalidCAFile(t *testing.T) {
+	_, err := NewClient("localhost", "user", "password", "1.7", TLSOptions{
+		Verify: true,
+		CAFile: filepath.Join(t.TempDir(), "missing.pem"),
+	})
+	if err == nil {
+		t.Errorf("NewClient accepts missing CA file")
+	}
+}
@@ -2,14 +2,24 @@ package netapp
 
 import (
 	"encoding/xml"
+	"fmt"
+	"strings"
+
+	n "github.com/pepabo/go-netapp/netapp"
 )
 
-func (c *Client) CheckCluster() (statusCode int, err error) {
-	body := c.ClusterIdentity
-	body.Params.XMLName = xml.Name{Local: "cluster-identity-get"}
-	resp, err := c.Do("POST", body)
-	if resp != nil {
-		statusCode = resp.StatusCode
+// CheckCluster requests the cluster identity to check whether the filer is
+// reachable and accepts the credentials. Only the http request has to
+// succeed, failures of the api call itself are not reported. The request is
+// built from a copy of the shared ClusterIdentity, since the check is run
+// concurrently by the supervisor and the probes.
+func (c *Client) CheckCluster() error {
+	req := &n.ClusterIdentity{Base: c.ClusterIdentity.Base}
+	req.Params.XMLName = xml.Name{Local: "cluster-identity-get"}
+	err := c.doRequest(req, &n.ClusterIdentityResponse{})
+	// go-netapp reports http errors only by their message
+	if err != nil && strings.Contains(err.Error(), "Http Error status 401") {
+		return fmt.Errorf("%w: %s", ErrAuthentication, err)
 	}
-	return
+	return err
 }
@@ -0,0 +1,160 @@
+package netapp
+
+import (
+	"encoding/xml"
+
+	n "github.com/pepabo/go-netapp/netapp"
+)
+
+type Disk struct {
+	Name             string
+	Node             string
+	HomeNode         string
+	ContainerType    string
+	DiskType         string
+	Model            string
+	FirmwareRevision string
+	Shelf            string
+	ShelfBay         string
+	Capacity         float64
+	IsFailed         bool
+	IsPrefailed      bool
+}
+
+type SpareDisk struct {
+	Name          string
+	Node          string
+	DiskType      string
+	UsableSize    float64
+	IsDiskZeroed  bool
+	IsDiskZeroing bool
+}
+
+// storageDiskGetIterRequest is used instead of StorageDisk.StorageDiskGetAll(),
+// because the go-netapp response type lacks the disk-raid-info attributes.
+type storageDiskGetIterRequest struct {
+	n.Base
+	Params struct {
+		XMLName xml.Name
+		*n.StorageDiskOptions
+	}
+}
+
+type storageDiskGetIterResponse struct {
+	XMLName xml.Name `xml:"netapp"`
+	Results struct {
+		n.ResultBase
+		AttributesList struct {
+			StorageDiskInfo []storageDiskInfo `xml:"storage-disk-info"`
+		} `xml:"attributes-list"`
+		NextTag string `xml:"next-tag"`
+	} `xml:"results"`
+}
+
+type storageDiskInfo struct {
+	n.StorageDiskInfo
+	DiskRaidInfo *diskRaidInfo `xml:"disk-raid-info"`
+}
+
+type diskRaidInfo struct {
+	ContainerType     string `xml:"container-type"`
+	DiskAggregateInfo *struct {
+		IsPrefailed bool `xml:"is-prefailed"`
+	} `xml:"disk-aggregate-info"`
+	DiskSpareInfo *struct {
+		IsPrefailed bool `xml:"is-prefailed"`
+	} `xml:"disk-spare-info"`
+}
+
+func (c *Client) ListDisks() (disks []*Disk, err error) {
+	diskInfos, err := c.listDisks()
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range diskInfos {
+		disks = append(disks, parseDisk(d))
+	}
+	return
+}
+
+func (c *Client) listDisks() (res []storageDiskInfo, err error) {
+	opts := &n.StorageDiskOptions{MaxRecords: 500}
+	for {
+		req := &storageDiskGetIterRequest{Base: c.StorageDisk.Base}
+		req.Params.XMLName = xml.Name{Local: "storage-disk-get-iter"}
+		req.Params.StorageDiskOptions = opts
+		resp := storageDiskGetIterResponse{}
+		if err = c.doRequest(req, &resp); err != nil {
+			return nil, err
+		}
+		if err = checkResult(&resp.Results.ResultBase); err != nil {
+			return nil, err
+		}
+		res = append(res, resp.Results.AttributesList.StorageDiskInfo...)
+		if resp.Results.NextTag == "" {
+			return
+		}
+		opts = &n.StorageDiskOptions{
+			MaxRecords: opts.MaxRecords,
+			Tag:        resp.Results.NextTag,
+		}
+	}
+}
+
+func parseDisk(info storageDiskInfo) *Disk {
+	disk := &Disk{
+		Name: info.DiskName,
+	}
+	if inventory := info.DiskInventoryInfo; inventory != nil {
+		disk.DiskType = inventory.DiskType
+		disk.Model = inventory.Model
+		disk.FirmwareRevision = inventory.FirmwareRevision
+		disk.Shelf = inventory.Shelf
+		disk.ShelfBay = inventory.ShelfBay
+		disk.Capacity = float64(inventory.CapacitySectors) * float64(inventory.BytesPerSector)
+	}
+	if ownership := info.DiskOwnershipInfo; ownership != nil {
+		disk.Node = ownership.OwnerNodeName
+		disk.HomeNode = ownership.HomeNodeName
+		disk.IsFailed = ownership.IsFailed != nil && *ownership.IsFailed
+	}
+	if raid := info.DiskRaidInfo; raid != nil {
+		disk.ContainerType = raid.ContainerType
+		if raid.DiskAggregateInfo != nil && raid.DiskAggregateInfo.IsPrefailed {
+			disk.IsPrefailed = true
+		}
+		if raid.DiskSpareInfo != nil && raid.DiskSpareInfo.IsPrefailed {
+			disk.IsPrefailed = true
+		}
+	}
+	return disk
+}
+
+func (c *Client) ListSpareDisks() (spares []*SpareDisk, err error) {
+	opts := &n.AggrSparesOptions{MaxRecords: 500}
+	pageHandler := func(r n.AggrSparesListPagesResponse) bool {
+		if r.Error != nil {
+			err = r.Error
+			return false
+		}
+		if err = checkResult(&r.Response.Results.ResultBase); err != nil {
+			return false
+		}
+		for _, s := range r.Response.Results.AttributesList.AggrAttributes {
+			spares = append(spares, &SpareDisk{
+				Name:          s.Disk,
+				Node:          s.OriginalOwner,
+				DiskType:      s.DiskType,
+				UsableSize:    float64(s.UsableSize),
+				IsDiskZeroed:  s.IsDiskZeroed,
+				IsDiskZeroing: s.IsDiskZeroing,
+			})
+		}
+		return true
+	}
+	c.AggregateSpares.ListPages(opts, pageHandler)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
@@ -0,0 +1,114 @@
+package netapp
+
+import (
+	"encoding/xml"
+
+	n "github.com/pepabo/go-netapp/netapp"
+)
+
+// EnvironmentSensor holds a sensor reading. Thresholds which are not set for
+// the sensor are nil.
+type EnvironmentSensor struct {
+	Node                  string
+	Name                  string
+	Type                  string
+	ValueUnits            string
+	Value                 float64
+	State                 string
+	WarningLowThreshold   *float64
+	WarningHighThreshold  *float64
+	CriticalLowThreshold  *float64
+	CriticalHighThreshold *float64
+}
+
+// environmentSensorsGetIterRequest is used instead of
+// EnvironmentSensors.ListPages(), because go-netapp decodes missing thresholds
+// as 0, which is also a valid threshold.
+type environmentSensorsGetIterRequest struct {
+	n.Base
+	Params struct {
+		XMLName xml.Name
+		*n.EnvironmentSensorsOptions
+	}
+}
+
+type environmentSensorsGetIterResponse struct {
+	XMLName xml.Name `xml:"netapp"`
+	Results struct {
+		n.ResultBase
+		AttributesList struct {
+			EnvironmentSensorsInfo []environmentSensorsInfo `xml:"environment-sensors-info"`
+		} `xml:"attributes-list"`
+		NextTag string `xml:"next-tag"`
+	} `xml:"results"`
+}
+
+// environmentSensorsInfo shadows the thresholds of go-netapp's
+// EnvironmentSensorsInfo, so that missing thresholds can be told apart.
+type environmentSensorsInfo struct {
+	n.EnvironmentSensorsInfo
+	CriticalHighThreshold *int `xml:"critical-high-threshold"`
+	CriticalLowThreshold  *int `xml:"critical-low-threshold"`
+	WarningHighThreshold  *int `xml:"warning-high-threshold"`
+	WarningLowThreshold   *int `xml:"warning-low-threshold"`
+}
+
+func (c *Client) ListEnvironmentSensors() (sensors []*EnvironmentSensor, err error) {
+	sensorInfos, err := c.listEnvironmentSensors()
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range sensorInfos {
+		sensors = append(sensors, parseEnvironmentSensor(s))
+	}
+	return
+}
+
+func (c *Client) listEnvironmentSensors() (res []environmentSensorsInfo, err error) {
+	opts := &n.EnvironmentSensorsOptions{MaxRecords: 500}
+	for {
+		req := &environmentSensorsGetIterRequest{Base: c.EnvironmentSensors.Base}
+		req.Params.XMLName = xml.Name{Local: "environment-sensors-get-iter"}
+		req.Params.EnvironmentSensorsOptions = opts
+		resp := environmentSensorsGetIterResponse{}
+		if err = c.doRequest(req, &resp); err != nil {
+			return nil, err
+		}
+		if err = checkResult(&resp.Results.ResultBase); err != nil {
+			return nil, err
+		}
+		res = append(res, resp.Results.AttributesList.EnvironmentSensorsInfo...)
+		if resp.Results.NextTag == "" {
+			return
+		}
+		opts = &n.EnvironmentSensorsOptions{MaxRecords: 500, Tag: resp.Results.NextTag}
+	}
+}
+
+func parseEnvironmentSensor(info environmentSensorsInfo) *EnvironmentSensor {
+	// discrete sensors, e.g. power supply presence, have no thresholds
+	state := info.ThresholdSensorState
+	if info.SensorType == "discrete" {
+		state = info.DiscreteSensorState
+	}
+	return &EnvironmentSensor{
+		Node:                  info.NodeName,
+		Name:                  info.SensorName,
+		Type:                  info.SensorType,
+		ValueUnits:            info.ValueUnits,
+		Value:                 float64(info.ThresholdSensorValue),
+		State:                 state,
+		WarningLowThreshold:   parseThreshold(info.WarningLowThreshold),
+		WarningHighThreshold:  parseThreshold(info.WarningHighThreshold),
+		CriticalLowThreshold:  parseThreshold(info.CriticalLowThreshold),
+		CriticalHighThreshold: parseThreshold(info.CriticalHighThreshold),
+	}
+}
+
+func parseThreshold(v *int) *float64 {
+	if v == nil {
+		return nil
+	}
+	f := float64(*v)
+	return &f
+}
@@ -0,0 +1,58 @@
+package netapp
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"strings"
+)
+
+// Reasons of failed requests to the filer, as returned by ErrorReason().
+const (
+	ErrorReasonDNS     = "dns"
+	ErrorReasonAuth    = "auth"
+	ErrorReasonTimeout = "timeout"
+	ErrorReasonTLS     = "tls"
+	ErrorReasonAPI     = "api"
+)
+
+// ErrAuthentication is returned if the filer rejects the credentials.
+var ErrAuthentication = errors.New("authentication failed")
+
+// ErrorReason classifies the error of a request to the filer. Errors, which are
+// not caused by name resolution, authentication, timeouts or tls, are reported
+// as api errors.
+func ErrorReason(err error) string {
+	var dnsError *net.DNSError
+	var netError net.Error
+	var unknownAuthorityError x509.UnknownAuthorityError
+	var hostnameError x509.HostnameError
+	var certificateInvalidError x509.CertificateInvalidError
+	var recordHeaderError tls.RecordHeaderError
+	switch {
+	case errors.As(err, &dnsError):
+		return ErrorReasonDNS
+	case errors.Is(err, ErrAuthentication):
+		return ErrorReasonAuth
+	case errors.Is(err, context.DeadlineExceeded):
+		return ErrorReasonTimeout
+	case errors.As(err, &netError) && netError.Timeout():
+		return ErrorReasonTimeout
+	case errors.As(err, &unknownAuthorityError),
+		errors.As(err, &hostnameError),
+		errors.As(err, &certificateInvalidError),
+		errors.As(err, &recordHeaderError):
+		return ErrorReasonTLS
+	}
+	// go-netapp reports http errors only by their message
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "Http Error status 401"):
+		return ErrorReasonAuth
+	case strings.Contains(msg, "tls: "), strings.Contains(msg, "x509: "):
+		return ErrorReasonTLS
+	}
+	return ErrorReasonAPI
+}
@@ -0,0 +1,42 @@
+package netapp
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+)
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestErrorReason(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		reason string
+	}{
+		{"dns", &net.DNSError{Err: "no such host", Name: "filer.example.com"}, ErrorReasonDNS},
+		{"wrapped dns", fmt.Errorf("Post https://filer: %w", &net.DNSError{Err: "no such host"}), ErrorReasonDNS},
+		{"authentication", ErrAuthentication, ErrorReasonAuth},
+		{"wrapped authentication", fmt.Errorf("check cluster: %w", ErrAuthentication), ErrorReasonAuth},
+		{"http 401", errors.New("Http Error status 401 Unauthorized"), ErrorReasonAuth},
+		{"deadline exceeded", context.DeadlineExceeded, ErrorReasonTimeout},
+		{"net timeout", &net.OpError{Op: "dial", Err: timeoutError{}}, ErrorReasonTimeout},
+		{"unknown authority", x509.UnknownAuthorityError{}, ErrorReasonTLS},
+		{"hostname", fmt.Errorf("Post https://filer: %w", x509.HostnameError{Certificate: &x509.Certificate{}, Host: "filer"}), ErrorReasonTLS},
+		{"tls message", errors.New("remote error: tls: bad certificate"), ErrorReasonTLS},
+		{"x509 message", errors.New("x509: certificate has expired"), ErrorReasonTLS},
+		{"api", errors.New("Unable to find API: volume-foo-iter"), ErrorReasonAPI},
+	}
+	for _, tt := range tests {
+		if reason := ErrorReason(tt.err); reason != tt.reason {
+			t.Errorf("%s: ErrorReason(%v) = %q, want %q", tt.name, tt.err, reason, tt.reason)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package netapp
+
+import (
+	n "github.com/pepabo/go-netapp/netapp"
+)
+
+type NodeFailover struct {
+	Node                  string
+	Partner               string
+	CurrentMode           string
+	NodeState             string
+	TakeoverState         string
+	GivebackState         string
+	TakeoverEnabled       bool
+	TakeoverPossible      bool
+	TakeoverFailureReason string
+	InterconnectType      string
+	InterconnectUp        bool
+}
+
+func (c *Client) ListNodeFailovers() (failovers []*NodeFailover, err error) {
+	failoverInfos, err := c.listNodeFailovers()
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range failoverInfos {
+		failovers = append(failovers, parseNodeFailover(f))
+	}
+	return
+}
+
+func (c *Client) listNodeFailovers() (res []n.StorageFailoverInfo, err error) {
+	opts := &n.ClusterFailoverInfoOptions{
+		MaxRecords: 100,
+	}
+	pageHandler := func(r n.ClusterFailoverInfoPagesResponse) bool {
+		if r.Error != nil {
+			err = r.Error
+			return false
+		}
+		if err = checkResult(&r.Response.Results.ResultBase); err != nil {
+			return false
+		}
+		res = append(res, r.Response.Results.AttributesList...)
+		return true
+	}
+	c.Cf.ClusterFailoverInfoListPages(opts, pageHandler)
+	return
+}
+
+func parseNodeFailover(info n.StorageFailoverInfo) *NodeFailover {
+	f := &NodeFailover{}
+	if node := info.NodeRelatedInfo; node != nil {
+		f.Node = node.Node
+		f.Partner = node.PartnerName
+		f.CurrentMode = node.CurrentMode
+		f.NodeState = node.NodeState
+	}
+	if takeover := info.TakeoverRelatedInfo; takeover != nil {
+		f.TakeoverState = takeover.TakeoverState
+		f.TakeoverEnabled = takeover.TakeoverEnabled
+		// takeover-of-partner-possible is what "storage failover show" reports
+		// as "Takeover Possible" for the node
+		f.TakeoverPossible = takeover.TakeoverOfPartnerPossible
+		f.TakeoverFailureReason = takeover.TakeoverFailureReason
+	}
+	if giveback := info.GivebackRelatedInfo; giveback != nil {
+		f.GivebackState = giveback.GivebackState
+	}
+	if interconnect := info.InterconnectRelatedInfo; interconnect != nil {
+		f.InterconnectType = interconnect.InterconnectType
+		f.InterconnectUp = interconnect.IsInterconnectUp
+	}
+	return f
+}
@@ -0,0 +1,80 @@
+package netapp
+
+import (
+	"strconv"
+
+	n "github.com/pepabo/go-netapp/netapp"
+)
+
+// FcpAdapter holds the attributes of a fibre channel target adapter. Speeds
+// are in gigabits per second; the configured speed is 0 if it is set to auto.
+type FcpAdapter struct {
+	Node              string
+	Adapter           string
+	WWPN              string
+	State             string
+	MediaType         string
+	SwitchPort        string
+	ConfiguredSpeed   float64
+	NegotiatedSpeed   float64
+	MaxSpeed          float64
+	PhysicalLinkState string
+	LinkState         string
+}
+
+// ListFcpAdapters returns the fc adapters of all nodes, together with the link
+// state of their ports.
+func (c *Client) ListFcpAdapters() (adapters []*FcpAdapter, err error) {
+	opts := &n.FcpAdapterConfigOptions{
+		MaxRecords: 100,
+	}
+	pageHandler := func(r n.FcpAdapterConfigPageResponse) bool {
+		if r.Error != nil {
+			err = r.Error
+			return false
+		}
+		if err = checkResult(&r.Response.Results.ResultBase); err != nil {
+			return false
+		}
+		for _, a := range r.Response.Results.AttributesList.FcpAdapterAttributes {
+			configuredSpeed, _ := strconv.ParseFloat(a.Speed, 64)
+			adapters = append(adapters, &FcpAdapter{
+				Node:              a.Node,
+				Adapter:           a.Adapter,
+				WWPN:              a.PortName,
+				State:             a.State,
+				MediaType:         a.MediaType,
+				SwitchPort:        a.SwitchPort,
+				ConfiguredSpeed:   configuredSpeed,
+				NegotiatedSpeed:   float64(a.DataLinkRate),
+				MaxSpeed:          float64(a.MaxSpeed),
+				PhysicalLinkState: a.PhysicalLinkState,
+			})
+		}
+		return true
+	}
+	c.Fcp.FcpAdapterGetAll(opts, pageHandler)
+	if err != nil {
+		return nil, err
+	}
+	// filers without fc adapters are not asked for link states
+	if len(adapters) == 0 {
+		return
+	}
+
+	resp, _, err := c.Fcport.GetLinkState(&n.FcportGetLinkStateOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if err = checkResult(&resp.Results.ResultBase); err != nil {
+		return nil, err
+	}
+	linkStates := make(map[string]string)
+	for _, l := range resp.Results.AdapterLinkState {
+		linkStates[l.NodeName+"/"+l.AdapterName] = l.LinkState
+	}
+	for _, a := range adapters {
+		a.LinkState = linkStates[a.Node+"/"+a.Adapter]
+	}
+	return
+}
@@ -0,0 +1,53 @@
+package netapp
+
+import (
+	n "github.com/pepabo/go-netapp/netapp"
+)
+
+type HealthAlert struct {
+	ID                string
+	Node              string
+	Monitor           string
+	Subsystem         string
+	AlertingResource  string
+	PerceivedSeverity string
+	ProbableCause     string
+	Acknowledged      bool
+	Suppressed        bool
+	IndicationTime    float64
+}
+
+func (c *Client) ListHealthAlerts() (alerts []*HealthAlert, err error) {
+	opts := &n.DiagnosisOptions{
+		MaxRecords: 500,
+	}
+	pageHandler := func(r n.DiagnosisAlertPagesResponse) bool {
+		if r.Error != nil {
+			err = r.Error
+			return false
+		}
+		if err = checkResult(&r.Response.Results.ResultBase); err != nil {
+			return false
+		}
+		for _, a := range r.Response.Results.AttributesList.DiagnosisAttributes {
+			alerts = append(alerts, &HealthAlert{
+				ID:                a.AlertId,
+				Node:              a.Node,
+				Monitor:           a.Monitor,
+				Subsystem:         a.Subsystem,
+				AlertingResource:  a.AlertingResource,
+				PerceivedSeverity: a.PerceivedSeverity,
+				ProbableCause:     a.ProbableCause,
+				Acknowledged:      a.Acknowledge,
+				Suppressed:        a.Suppress,
+				IndicationTime:    float64(a.IndicationTime),
+			})
+		}
+		return true
+	}
+	c.Diagnosis.DiagnosisAlertGetAll(opts, pageHandler)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
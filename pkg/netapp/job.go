@@ -0,0 +1,153 @@
+package netapp
+
+import (
+	"encoding/xml"
+	"fmt"
+
+	n "github.com/pepabo/go-netapp/netapp"
+)
+
+type JobEvent struct {
+	LogID     int
+	JobID     int
+	Name      string
+	Node      string
+	Vserver   string
+	EventType string
+	EventTime int64
+}
+
+type RunningJob struct {
+	ID        string
+	Name      string
+	Type      string
+	Node      string
+	Vserver   string
+	StartTime float64
+}
+
+// jobHistoryGetIterRequest is used instead of Job.GetHistory(), because the
+// go-netapp response type lacks the next-tag and the query cannot express a
+// time range.
+type jobHistoryGetIterRequest struct {
+	n.Base
+	Params struct {
+		XMLName xml.Name
+		Query   struct {
+			JobEventTime string `xml:"job-event-time,omitempty"`
+		} `xml:"query>job-history-info"`
+		MaxRecords int    `xml:"max-records,omitempty"`
+		Tag        string `xml:"tag,omitempty"`
+	}
+}
+
+type jobHistoryGetIterResponse struct {
+	XMLName xml.Name `xml:"netapp"`
+	Results struct {
+		n.ResultBase
+		AttributesList struct {
+			JobHistoryInfo []n.JobHistoryInfo `xml:"job-history-info"`
+		} `xml:"attributes-list"`
+		NextTag string `xml:"next-tag"`
+	} `xml:"results"`
+}
+
+// job-get-iter is not implemented in go-netapp
+type jobGetIterRequest struct {
+	n.Base
+	Params struct {
+		XMLName xml.Name
+		Query   struct {
+			JobState string `xml:"job-state,omitempty"`
+		} `xml:"query>job-info"`
+		MaxRecords int    `xml:"max-records,omitempty"`
+		Tag        string `xml:"tag,omitempty"`
+	}
+}
+
+type jobGetIterResponse struct {
+	XMLName xml.Name `xml:"netapp"`
+	Results struct {
+		n.ResultBase
+		AttributesList struct {
+			JobInfo []jobInfo `xml:"job-info"`
+		} `xml:"attributes-list"`
+		NextTag string `xml:"next-tag"`
+	} `xml:"results"`
+}
+
+type jobInfo struct {
+	JobID        string `xml:"job-id"`
+	JobName      string `xml:"job-name"`
+	JobType      string `xml:"job-type"`
+	JobNode      string `xml:"job-node"`
+	JobVserver   string `xml:"job-vserver"`
+	JobStartTime int64  `xml:"job-start-time"`
+}
+
+// ListJobEvents returns the job history events which happened at or after the
+// given unix time.
+func (c *Client) ListJobEvents(since int64) (events []*JobEvent, err error) {
+	tag := ""
+	for {
+		req := &jobHistoryGetIterRequest{Base: c.Job.Base}
+		req.Params.XMLName = xml.Name{Local: "job-history-get-iter"}
+		req.Params.Query.JobEventTime = fmt.Sprintf(">=%d", since)
+		req.Params.MaxRecords = 500
+		req.Params.Tag = tag
+		resp := jobHistoryGetIterResponse{}
+		if err = c.doRequest(req, &resp); err != nil {
+			return nil, err
+		}
+		if err = checkResult(&resp.Results.ResultBase); err != nil {
+			return nil, err
+		}
+		for _, j := range resp.Results.AttributesList.JobHistoryInfo {
+			events = append(events, &JobEvent{
+				LogID:     j.LogID,
+				JobID:     j.JobID,
+				Name:      j.JobName,
+				Node:      j.JobNode,
+				Vserver:   j.JobVServer,
+				EventType: j.JobEventType,
+				EventTime: int64(j.JobEventTime),
+			})
+		}
+		if resp.Results.NextTag == "" {
+			return
+		}
+		tag = resp.Results.NextTag
+	}
+}
+
+func (c *Client) ListRunningJobs() (jobs []*RunningJob, err error) {
+	tag := ""
+	for {
+		req := &jobGetIterRequest{Base: c.Job.Base}
+		req.Params.XMLName = xml.Name{Local: "job-get-iter"}
+		req.Params.Query.JobState = "running"
+		req.Params.MaxRecords = 500
+		req.Params.Tag = tag
+		resp := jobGetIterResponse{}
+		if err = c.doRequest(req, &resp); err != nil {
+			return nil, err
+		}
+		if err = checkResult(&resp.Results.ResultBase); err != nil {
+			return nil, err
+		}
+		for _, j := range resp.Results.AttributesList.JobInfo {
+			jobs = append(jobs, &RunningJob{
+				ID:        j.JobID,
+				Name:      j.JobName,
+				Type:      j.JobType,
+				Node:      j.JobNode,
+				Vserver:   j.JobVserver,
+				StartTime: float64(j.JobStartTime),
+			})
+		}
+		if resp.Results.NextTag == "" {
+			return
+		}
+		tag = resp.Results.NextTag
+	}
+}
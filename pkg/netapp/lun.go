@@ -0,0 +1,98 @@
+package netapp
+
+import (
+	"encoding/xml"
+
+	n "github.com/pepabo/go-netapp/netapp"
+)
+
+type Lun struct {
+	Path                      string
+	Vserver                   string
+	Volume                    string
+	Qtree                     string
+	Node                      string
+	State                     string
+	Size                      float64
+	SizeUsed                  float64
+	Online                    bool
+	Mapped                    bool
+	IsSpaceReservationEnabled bool
+	// nil if not reported by the filer
+	IsThinProvisioned *bool
+}
+
+// lunGetIterRequest is used instead of Lun.ListPages(), because go-netapp's
+// LunInfo lacks the thin provisioning attribute.
+type lunGetIterRequest struct {
+	n.Base
+	Params struct {
+		XMLName xml.Name
+		*n.LunOptions
+	}
+}
+
+type lunGetIterResponse struct {
+	XMLName xml.Name `xml:"netapp"`
+	Results struct {
+		n.ResultBase
+		AttributesList struct {
+			LunInfo []lunInfo `xml:"lun-info"`
+		} `xml:"attributes-list"`
+		NextTag string `xml:"next-tag"`
+	} `xml:"results"`
+}
+
+type lunInfo struct {
+	n.LunInfo
+	IsThinProvisioned *bool `xml:"is-thin-provisioned"`
+}
+
+func (c *Client) ListLuns() (luns []*Lun, err error) {
+	lunInfos, err := c.listLuns()
+	if err != nil {
+		return nil, err
+	}
+	for _, l := range lunInfos {
+		luns = append(luns, parseLun(l))
+	}
+	return
+}
+
+func (c *Client) listLuns() (res []lunInfo, err error) {
+	opts := &n.LunOptions{MaxRecords: 500}
+	for {
+		req := &lunGetIterRequest{Base: c.Lun.Base}
+		req.Params.XMLName = xml.Name{Local: "lun-get-iter"}
+		req.Params.LunOptions = opts
+		resp := lunGetIterResponse{}
+		if err = c.doRequest(req, &resp); err != nil {
+			return nil, err
+		}
+		if err = checkResult(&resp.Results.ResultBase); err != nil {
+			return nil, err
+		}
+		res = append(res, resp.Results.AttributesList.LunInfo...)
+		if resp.Results.NextTag == "" {
+			return
+		}
+		opts = &n.LunOptions{MaxRecords: 500, Tag: resp.Results.NextTag}
+	}
+}
+
+func parseLun(info lunInfo) *Lun {
+	return &Lun{
+		Path:                      info.Path,
+		Vserver:                   info.Vserver,
+		Volume:                    info.Volume,
+		Qtree:                     info.Qtree,
+		Node:                      info.Node,
+		State:                     info.State,
+		Size:                      float64(info.Size),
+		SizeUsed:                  float64(info.SizeUsed),
+		Online:                    info.Online,
+		Mapped:                    info.Mapped,
+		IsSpaceReservationEnabled: info.IsSpaceReservationEnabled,
+		IsThinProvisioned:         info.IsThinProvisioned,
+	}
+}
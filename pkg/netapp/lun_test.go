@@ -0,0 +1,32 @@
+package netapp
+
+import (
+	"encoding/xml"
+	"testing"
+)
+
+func TestParseLunThinProvisioned(t *testing.T) {
+	tests := []struct {
+		name string
+		xml  string
+		want *bool
+	}{
+		{"thin and space reserved", `<lun-info><path>/vol/v1/lun1</path><is-space-reservation-enabled>true</is-space-reservation-enabled><is-thin-provisioned>true</is-thin-provisioned></lun-info>`, boolPtr(true)},
+		{"thick", `<lun-info><path>/vol/v1/lun2</path><is-thin-provisioned>false</is-thin-provisioned></lun-info>`, boolPtr(false)},
+		{"not reported", `<lun-info><path>/vol/v1/lun3</path></lun-info>`, nil},
+	}
+	for _, tt := range tests {
+		var info lunInfo
+		if err := xml.Unmarshal([]byte(tt.xml), &info); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		got := parseLun(info).IsThinProvisioned
+		if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+			t.Errorf("%s: IsThinProvisioned = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
@@ -0,0 +1,154 @@
+package netapp
+
+import (
+	"encoding/xml"
+	"strconv"
+
+	n "github.com/pepabo/go-netapp/netapp"
+)
+
+// NetPort holds the attributes of a network port. Speed is the operational
+// speed in megabits per second, or 0 if it is unknown.
+type NetPort struct {
+	Node            string
+	Port            string
+	PortType        string
+	Role            string
+	LinkStatus      string
+	HealthStatus    string
+	BroadcastDomain string
+	IPSpace         string
+	Speed           float64
+	Mtu             float64
+	IsAdminUp       bool
+}
+
+type NetInterface struct {
+	Name             string
+	Vserver          string
+	Role             string
+	Address          string
+	AdministrativeUp bool
+	OperationalUp    bool
+	IsHome           bool
+	IsAutoRevert     bool
+	CurrentNode      string
+	CurrentPort      string
+	HomeNode         string
+	HomePort         string
+}
+
+// netPortGetIterRequest is used instead of Net.NetPortGetAll(), because the
+// go-netapp response type lacks the health-status and broadcast-domain
+// attributes.
+type netPortGetIterRequest struct {
+	n.Base
+	Params struct {
+		XMLName xml.Name
+		*n.NetPortOptions
+	}
+}
+
+type netPortGetIterResponse struct {
+	XMLName xml.Name `xml:"netapp"`
+	Results struct {
+		n.ResultBase
+		AttributesList struct {
+			NetPortInfo []netPortInfo `xml:"net-port-info"`
+		} `xml:"attributes-list"`
+		NextTag string `xml:"next-tag"`
+	} `xml:"results"`
+}
+
+type netPortInfo struct {
+	n.NetPortInfo
+	HealthStatus    string `xml:"health-status"`
+	BroadcastDomain string `xml:"broadcast-domain"`
+	IPSpace         string `xml:"ipspace"`
+}
+
+func (c *Client) ListNetPorts() (ports []*NetPort, err error) {
+	portInfos, err := c.listNetPorts()
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range portInfos {
+		// speed is "auto" or "undef" if the link is down
+		speed, _ := strconv.ParseFloat(p.OperationalSpeed, 64)
+		ports = append(ports, &NetPort{
+			Node:            p.Node,
+			Port:            p.Port,
+			PortType:        p.PortType,
+			Role:            p.Role,
+			LinkStatus:      p.LinkStatus,
+			HealthStatus:    p.HealthStatus,
+			BroadcastDomain: p.BroadcastDomain,
+			IPSpace:         p.IPSpace,
+			Speed:           speed,
+			Mtu:             float64(p.Mtu),
+			IsAdminUp:       p.IsAdministrativeUp,
+		})
+	}
+	return
+}
+
+func (c *Client) listNetPorts() (res []netPortInfo, err error) {
+	opts := &n.NetPortOptions{MaxRecords: 500}
+	for {
+		req := &netPortGetIterRequest{Base: c.Net.Base}
+		req.Params.XMLName = xml.Name{Local: "net-port-get-iter"}
+		req.Params.NetPortOptions = opts
+		resp := netPortGetIterResponse{}
+		if err = c.doRequest(req, &resp); err != nil {
+			return nil, err
+		}
+		if err = checkResult(&resp.Results.ResultBase); err != nil {
+			return nil, err
+		}
+		res = append(res, resp.Results.AttributesList.NetPortInfo...)
+		if resp.Results.NextTag == "" {
+			return
+		}
+		opts = &n.NetPortOptions{
+			MaxRecords: opts.MaxRecords,
+			Tag:        resp.Results.NextTag,
+		}
+	}
+}
+
+func (c *Client) ListNetInterfaces() (lifs []*NetInterface, err error) {
+	opts := &n.NetInterfaceOptions{
+		MaxRecords: 500,
+	}
+	pageHandler := func(r n.NetInterfacePageResponse) bool {
+		if r.Error != nil {
+			err = r.Error
+			return false
+		}
+		if err = checkResult(&r.Response.Results.ResultBase); err != nil {
+			return false
+		}
+		for _, l := range r.Response.Results.AttributesList.NetInterfaceAttributes {
+			lifs = append(lifs, &NetInterface{
+				Name:             l.InterfaceName,
+				Vserver:          l.Vserver,
+				Role:             l.Role,
+				Address:          l.Address,
+				AdministrativeUp: l.AdministrativeStatus == "up",
+				OperationalUp:    l.OperationalStatus == "up",
+				IsHome:           l.IsHome,
+				IsAutoRevert:     l.IsAutoRevert,
+				CurrentNode:      l.CurrentNode,
+				CurrentPort:      l.CurrentPort,
+				HomeNode:         l.HomeNode,
+				HomePort:         l.HomePort,
+			})
+		}
+		return true
+	}
+	c.Net.NetInterfaceGetAll(opts, pageHandler)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
@@ -0,0 +1,79 @@
+package netapp
+
+import (
+	"time"
+)
+
+// NodePerf is a sample of the raw perf counters of a node. The processor and
+// disk counters are summed up over all processors and disks of the node, so
+// that the busy counters divided by their bases give the average utilisation.
+type NodePerf struct {
+	Node                 string
+	Timestamp            time.Time
+	CPUBusy              uint64
+	CPUElapsedTime       uint64
+	NFSOps               uint64
+	CIFSOps              uint64
+	ISCSIOps             uint64
+	FCPOps               uint64
+	TotalOps             uint64
+	ProcessorBusy        uint64
+	ProcessorElapsedTime uint64
+	ProcessorCount       int
+	DiskBusy             uint64
+	DiskBusyBase         uint64
+	DiskCount            int
+}
+
+var (
+	systemNodePerfCounters = []string{"cpu_busy", "cpu_elapsed_time", "nfs_ops", "cifs_ops", "iscsi_ops", "fcp_ops", "total_ops"}
+	processorPerfCounters  = []string{"node_name", "processor_busy", "processor_elapsed_time"}
+	diskPerfCounters       = []string{"node_name", "disk_busy", "base_for_disk_busy"}
+)
+
+func (c *Client) ListNodePerf() (perfs []*NodePerf, err error) {
+	systemInstances, err := c.ListPerfInstances("system:node", systemNodePerfCounters)
+	if err != nil {
+		return nil, err
+	}
+	processorInstances, err := c.ListPerfInstances("processor", processorPerfCounters)
+	if err != nil {
+		return nil, err
+	}
+	diskInstances, err := c.ListPerfInstances("disk", diskPerfCounters)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := make(map[string]*NodePerf)
+	for _, instance := range systemInstances {
+		p := &NodePerf{
+			Node:           instance.Name,
+			Timestamp:      instance.Timestamp,
+			CPUBusy:        parsePerfCounter(instance, "cpu_busy"),
+			CPUElapsedTime: parsePerfCounter(instance, "cpu_elapsed_time"),
+			NFSOps:         parsePerfCounter(instance, "nfs_ops"),
+			CIFSOps:        parsePerfCounter(instance, "cifs_ops"),
+			ISCSIOps:       parsePerfCounter(instance, "iscsi_ops"),
+			FCPOps:         parsePerfCounter(instance, "fcp_ops"),
+			TotalOps:       parsePerfCounter(instance, "total_ops"),
+		}
+		nodes[p.Node] = p
+		perfs = append(perfs, p)
+	}
+	for _, instance := range processorInstances {
+		if p, ok := nodes[instance.Counters["node_name"]]; ok {
+			p.ProcessorBusy += parsePerfCounter(instance, "processor_busy")
+			p.ProcessorElapsedTime += parsePerfCounter(instance, "processor_elapsed_time")
+			p.ProcessorCount++
+		}
+	}
+	for _, instance := range diskInstances {
+		if p, ok := nodes[instance.Counters["node_name"]]; ok {
+			p.DiskBusy += parsePerfCounter(instance, "disk_busy")
+			p.DiskBusyBase += parsePerfCounter(instance, "base_for_disk_busy")
+			p.DiskCount++
+		}
+	}
+	return
+}
@@ -0,0 +1,125 @@
+package netapp
+
+import (
+	"encoding/xml"
+	"strconv"
+	"time"
+
+	n "github.com/pepabo/go-netapp/netapp"
+)
+
+// maximal number of instances queried in one perf-object-get-instances call
+const perfInstancesBatchSize = 500
+
+// PerfInstance holds the raw counter values of a perf object instance. Most
+// counters are cumulative, so rates have to be computed from two samples.
+type PerfInstance struct {
+	Name      string
+	Counters  map[string]string
+	Timestamp time.Time
+}
+
+// perfObjectGetInstancesRequest is used instead of Perf.PerfObjectGetInstances(),
+// because the go-netapp response type lacks the timestamp of the counters.
+type perfObjectGetInstancesRequest struct {
+	n.Base
+	Params struct {
+		XMLName xml.Name
+		*n.PerfObjectGetInstanceParams
+	}
+}
+
+type perfObjectGetInstancesResponse struct {
+	XMLName xml.Name `xml:"netapp"`
+	Results struct {
+		n.ResultBase
+		Instances []n.InstanceData `xml:"instances>instance-data"`
+		Timestamp int64            `xml:"timestamp"`
+	} `xml:"results"`
+}
+
+// ListPerfInstances returns the counters of all instances of the perf object.
+func (c *Client) ListPerfInstances(objectName string, counters []string) ([]*PerfInstance, error) {
+	uuids, err := c.listPerfInstanceUuids(objectName)
+	if err != nil {
+		return nil, err
+	}
+	return c.GetPerfInstances(objectName, uuids, counters)
+}
+
+// GetPerfInstances returns the counters of the perf object instances given by
+// their uuids.
+func (c *Client) GetPerfInstances(objectName string, uuids []string, counters []string) (res []*PerfInstance, err error) {
+	for start := 0; start < len(uuids); start += perfInstancesBatchSize {
+		end := start + perfInstancesBatchSize
+		if end > len(uuids) {
+			end = len(uuids)
+		}
+		params := &n.PerfObjectGetInstanceParams{ObjectName: objectName}
+		params.InstanceUuids.Uuids = uuids[start:end]
+		params.Counters.Counter = counters
+		req := &perfObjectGetInstancesRequest{Base: c.Perf.Base}
+		req.Params.XMLName = xml.Name{Local: "perf-object-get-instances"}
+		req.Params.PerfObjectGetInstanceParams = params
+		resp := perfObjectGetInstancesResponse{}
+		if err = c.doRequest(req, &resp); err != nil {
+			return nil, err
+		}
+		if err = checkResult(&resp.Results.ResultBase); err != nil {
+			return nil, err
+		}
+		// the counters are stamped with the filer's time of sampling, so that
+		// the duration of the request does not skew the rates
+		timestamp := time.Unix(resp.Results.Timestamp, 0)
+		if resp.Results.Timestamp == 0 {
+			timestamp = time.Now()
+		}
+		for _, instance := range resp.Results.Instances {
+			res = append(res, parsePerfInstance(instance, timestamp))
+		}
+	}
+	return
+}
+
+// listPerfInstanceUuids pages through perf-object-instance-list-info-iter.
+// PerfObjectInstanceGetAllInfo() is not used, because it drops the object name
+// when requesting the following pages.
+func (c *Client) listPerfInstanceUuids(objectName string) (uuids []string, err error) {
+	params := &n.PerfObjectInstanceListInfoIterParams{
+		ObjectName: objectName,
+		MaxRecords: perfInstancesBatchSize,
+	}
+	for {
+		resp, _, err := c.Perf.PerfObjectInstanceListInfoIter(params)
+		if err != nil {
+			return nil, err
+		}
+		if err = checkResult(&resp.Results.ResultBase); err != nil {
+			return nil, err
+		}
+		for _, info := range resp.Results.AttributesList.InstanceInfo {
+			uuids = append(uuids, info.Uuid)
+		}
+		if resp.Results.NextTag == "" {
+			return uuids, nil
+		}
+		params.Tag = resp.Results.NextTag
+	}
+}
+
+func parsePerfInstance(data n.InstanceData, timestamp time.Time) *PerfInstance {
+	counters := make(map[string]string, len(data.Counters.CounterData))
+	for _, d := range data.Counters.CounterData {
+		counters[d.Name] = d.Value
+	}
+	return &PerfInstance{
+		Name:      data.Name,
+		Counters:  counters,
+		Timestamp: timestamp,
+	}
+}
+
+func parsePerfCounter(instance *PerfInstance, name string) uint64 {
+	v, _ := strconv.ParseUint(instance.Counters[name], 10, 64)
+	return v
+}
@@ -0,0 +1,160 @@
+package netapp
+
+import (
+	"encoding/xml"
+	"strconv"
+	"strings"
+	"time"
+
+	n "github.com/pepabo/go-netapp/netapp"
+)
+
+// QosPolicyGroup holds the throughput limits of a qos policy group. Limits are
+// -1 if they are not set.
+type QosPolicyGroup struct {
+	Name               string
+	Vserver            string
+	Class              string
+	NumWorkloads       float64
+	MaxThroughputIOPS  float64
+	MaxThroughputBytes float64
+	MinThroughputIOPS  float64
+	MinThroughputBytes float64
+}
+
+// QosPolicyGroupPerf is a sample of the raw perf counters of a qos policy
+// group. Latency is in microseconds and has ops as base.
+type QosPolicyGroupPerf struct {
+	PolicyGroup string
+	Timestamp   time.Time
+	Ops         uint64
+	ReadData    uint64
+	WriteData   uint64
+	Latency     uint64
+}
+
+// qosPolicyGroupGetIterRequest is used, because the go-netapp QosPolicy
+// service has no get-iter call.
+type qosPolicyGroupGetIterRequest struct {
+	n.Base
+	Params struct {
+		XMLName    xml.Name
+		MaxRecords int    `xml:"max-records,omitempty"`
+		Tag        string `xml:"tag,omitempty"`
+	}
+}
+
+type qosPolicyGroupGetIterResponse struct {
+	XMLName xml.Name `xml:"netapp"`
+	Results struct {
+		n.ResultBase
+		AttributesList struct {
+			QosPolicyGroupInfo []qosPolicyGroupInfo `xml:"qos-policy-group-info"`
+		} `xml:"attributes-list"`
+		NextTag string `xml:"next-tag"`
+	} `xml:"results"`
+}
+
+type qosPolicyGroupInfo struct {
+	n.QosPolicyInfo
+	MinThroughput string `xml:"min-throughput"`
+}
+
+var qosPolicyGroupPerfCounters = []string{"ops", "read_data", "write_data", "latency"}
+
+func (c *Client) ListQosPolicyGroups() (groups []*QosPolicyGroup, err error) {
+	groupInfos, err := c.listQosPolicyGroups()
+	if err != nil {
+		return nil, err
+	}
+	for _, g := range groupInfos {
+		group := &QosPolicyGroup{
+			Name:         g.PolicyGroup,
+			Vserver:      g.VServer,
+			Class:        g.PolicyGroupClass,
+			NumWorkloads: float64(g.NumWorkloads),
+		}
+		group.MaxThroughputIOPS, group.MaxThroughputBytes = parseQosThroughput(g.MaxThroughput)
+		group.MinThroughputIOPS, group.MinThroughputBytes = parseQosThroughput(g.MinThroughput)
+		groups = append(groups, group)
+	}
+	return
+}
+
+func (c *Client) listQosPolicyGroups() (res []qosPolicyGroupInfo, err error) {
+	tag := ""
+	for {
+		req := &qosPolicyGroupGetIterRequest{Base: c.QosPolicy.Base}
+		req.Params.XMLName = xml.Name{Local: "qos-policy-group-get-iter"}
+		req.Params.MaxRecords = 500
+		req.Params.Tag = tag
+		resp := qosPolicyGroupGetIterResponse{}
+		if err = c.doRequest(req, &resp); err != nil {
+			return nil, err
+		}
+		if err = checkResult(&resp.Results.ResultBase); err != nil {
+			return nil, err
+		}
+		res = append(res, resp.Results.AttributesList.QosPolicyGroupInfo...)
+		if resp.Results.NextTag == "" {
+			return
+		}
+		tag = resp.Results.NextTag
+	}
+}
+
+func (c *Client) ListQosPolicyGroupPerf() (perfs []*QosPolicyGroupPerf, err error) {
+	instances, err := c.ListPerfInstances("policy_group", qosPolicyGroupPerfCounters)
+	if err != nil {
+		return nil, err
+	}
+	for _, instance := range instances {
+		perfs = append(perfs, &QosPolicyGroupPerf{
+			PolicyGroup: instance.Name,
+			Timestamp:   instance.Timestamp,
+			Ops:         parsePerfCounter(instance, "ops"),
+			ReadData:    parsePerfCounter(instance, "read_data"),
+			WriteData:   parsePerfCounter(instance, "write_data"),
+			Latency:     parsePerfCounter(instance, "latency"),
+		})
+	}
+	return
+}
+
+// qosThroughputUnits is ordered with the longest suffix first, because "B/S"
+// is a suffix of all other units.
+var qosThroughputUnits = []struct {
+	suffix string
+	factor float64
+}{
+	{"KB/S", 1024},
+	{"MB/S", 1024 * 1024},
+	{"GB/S", 1024 * 1024 * 1024},
+	{"TB/S", 1024 * 1024 * 1024 * 1024},
+	{"B/S", 1},
+}
+
+// parseQosThroughput parses throughput limits like "1000IOPS", "100MB/S" or
+// "1000IOPS,100MB/S". Limits which are not given, or given as "INF", are -1.
+func parseQosThroughput(s string) (iops, bytes float64) {
+	iops, bytes = -1, -1
+	for _, limit := range strings.Split(strings.ToUpper(s), ",") {
+		limit = strings.TrimSpace(limit)
+		if strings.HasSuffix(limit, "IOPS") {
+			if v, err := strconv.ParseFloat(strings.TrimSuffix(limit, "IOPS"), 64); err == nil {
+				iops = v
+			}
+			continue
+		}
+		for _, unit := range qosThroughputUnits {
+			if !strings.HasSuffix(limit, unit.suffix) {
+				continue
+			}
+			if v, err := strconv.ParseFloat(strings.TrimSuffix(limit, unit.suffix), 64); err == nil {
+				bytes = v * unit.factor
+			}
+			break
+		}
+	}
+	return
+}
@@ -0,0 +1,33 @@
+package netapp
+
+import "testing"
+
+func TestParseQosThroughput(t *testing.T) {
+	tests := []struct {
+		name  string
+		s     string
+		iops  float64
+		bytes float64
+	}{
+		{"empty", "", -1, -1},
+		{"unlimited", "INF", -1, -1},
+		{"iops", "1000IOPS", 1000, -1},
+		{"bytes", "100B/S", -1, 100},
+		{"kilobytes", "2KB/S", -1, 2 * 1024},
+		{"megabytes", "100MB/S", -1, 100 * 1024 * 1024},
+		{"gigabytes", "1.5GB/S", -1, 1.5 * 1024 * 1024 * 1024},
+		{"terabytes", "1TB/S", -1, 1024 * 1024 * 1024 * 1024},
+		{"lower case", "10mb/s", -1, 10 * 1024 * 1024},
+		{"iops and bytes", "1000IOPS,100MB/S", 1000, 100 * 1024 * 1024},
+		{"iops and bytes with space", "1000IOPS, 100MB/S", 1000, 100 * 1024 * 1024},
+		{"invalid number", "xMB/S", -1, -1},
+		{"unknown unit", "100PB/S", -1, -1},
+	}
+	for _, tt := range tests {
+		iops, bytes := parseQosThroughput(tt.s)
+		if iops != tt.iops || bytes != tt.bytes {
+			t.Errorf("%s: parseQosThroughput(%q) = (%v, %v), want (%v, %v)",
+				tt.name, tt.s, iops, bytes, tt.iops, tt.bytes)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package netapp
+
+import (
+	"encoding/xml"
+
+	n "github.com/pepabo/go-netapp/netapp"
+)
+
+type Qtree struct {
+	Qtree         string
+	Volume        string
+	Vserver       string
+	ID            string
+	SecurityStyle string
+	Oplocks       string
+	ExportPolicy  string
+	Status        string
+	ShareID       string
+	ShareName     string
+	ShareType     string
+	ProjectID     string
+}
+
+// qtreeListIterRequest is used instead of Qtree.List(), because the go-netapp
+// response type lacks the next-tag for paging.
+type qtreeListIterRequest struct {
+	n.Base
+	Params struct {
+		XMLName xml.Name
+		*n.QtreeOptions
+	}
+}
+
+type qtreeListIterResponse struct {
+	XMLName xml.Name `xml:"netapp"`
+	Results struct {
+		n.ResultBase
+		AttributesList struct {
+			QtreeInfo []qtreeInfo `xml:"qtree-info"`
+		} `xml:"attributes-list"`
+		NextTag string `xml:"next-tag"`
+	} `xml:"results"`
+}
+
+// qtreeInfo adds the comment, which is missing in go-netapp's QtreeInfo.
+type qtreeInfo struct {
+	n.QtreeInfo
+	Comment string `xml:"comment"`
+}
+
+// ListQtrees returns the qtrees of all volumes. The implicit qtree 0, which
+// represents the volume itself, is not returned.
+func (c *Client) ListQtrees() (qtrees []*Qtree, err error) {
+	qtreeInfos, err := c.listQtrees()
+	if err != nil {
+		return nil, err
+	}
+	for _, q := range qtreeInfos {
+		if q.Qtree == "" {
+			continue
+		}
+		qtrees = append(qtrees, parseQtree(q))
+	}
+	return
+}
+
+// parseQtree parses the qtree. The openstack labels are parsed from the
+// qtree's own comment, with the same convention as for volumes.
+func parseQtree(q qtreeInfo) *Qtree {
+	qtree := &Qtree{
+		Qtree:         q.Qtree,
+		Volume:        q.Volume,
+		Vserver:       q.Vserver,
+		ID:            q.ID,
+		SecurityStyle: q.SecurityStyle,
+		Oplocks:       q.Oplocks,
+		ExportPolicy:  q.ExportPolicy,
+		Status:        q.Status,
+	}
+	if q.Comment != "" {
+		shareID, shareName, shareType, projectID, err := parseVolumeComment(q.Comment)
+		if err == nil {
+			qtree.ShareID = shareID
+			qtree.ShareName = shareName
+			qtree.ShareType = shareType
+			qtree.ProjectID = projectID
+		}
+	}
+	return qtree
+}
+
+func (c *Client) listQtrees() (res []qtreeInfo, err error) {
+	opts := &n.QtreeOptions{MaxRecords: 500}
+	for {
+		req := &qtreeListIterRequest{Base: c.Qtree.Base}
+		req.Params.XMLName = xml.Name{Local: "qtree-list-iter"}
+		req.Params.QtreeOptions = opts
+		resp := qtreeListIterResponse{}
+		if err = c.doRequest(req, &resp); err != nil {
+			return nil, err
+		}
+		if err = checkResult(&resp.Results.ResultBase); err != nil {
+			return nil, err
+		}
+		res = append(res, resp.Results.AttributesList.QtreeInfo...)
+		if resp.Results.NextTag == "" {
+			return
+		}
+		opts = &n.QtreeOptions{
+			MaxRecords: opts.MaxRecords,
+			Tag:        resp.Results.NextTag,
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package netapp
+
+import (
+	"encoding/xml"
+	"testing"
+)
+
+func TestParseQtree(t *testing.T) {
+	tests := []struct {
+		name string
+		xml  string
+		want Qtree
+	}{
+		{
+			"openstack comment",
+			`<qtree-info><qtree>q1</qtree><volume>v1</volume><vserver>vs1</vserver><security-style>unix</security-style>` +
+				`<comment>share_id: 1234-abcd, share_name: my_share, share_type: default, project: 5678</comment></qtree-info>`,
+			Qtree{Qtree: "q1", Volume: "v1", Vserver: "vs1", SecurityStyle: "unix",
+				ShareID: "1234-abcd", ShareName: "my_share", ShareType: "default", ProjectID: "5678"},
+		},
+		{
+			"other comment",
+			`<qtree-info><qtree>q2</qtree><volume>v1</volume><vserver>vs1</vserver><comment>backup</comment></qtree-info>`,
+			Qtree{Qtree: "q2", Volume: "v1", Vserver: "vs1"},
+		},
+		{
+			"no comment",
+			`<qtree-info><qtree>q3</qtree><volume>v1</volume><vserver>vs1</vserver></qtree-info>`,
+			Qtree{Qtree: "q3", Volume: "v1", Vserver: "vs1"},
+		},
+	}
+	for _, tt := range tests {
+		var info qtreeInfo
+		if err := xml.Unmarshal([]byte(tt.xml), &info); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if got := parseQtree(info); *got != tt.want {
+			t.Errorf("%s: parseQtree() = %+v, want %+v", tt.name, *got, tt.want)
+		}
+	}
+}
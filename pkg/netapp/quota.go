@@ -0,0 +1,109 @@
+package netapp
+
+import (
+	"strconv"
+
+	n "github.com/pepabo/go-netapp/netapp"
+)
+
+// Quota is an entry of the quota report. Disk sizes are in bytes. Limits are
+// -1 if they are not set, i.e. unlimited.
+type Quota struct {
+	Vserver       string
+	Volume        string
+	Qtree         string
+	Type          string
+	Target        string
+	DiskUsed      float64
+	DiskLimit     float64
+	SoftDiskLimit float64
+	FilesUsed     float64
+	FileLimit     float64
+	SoftFileLimit float64
+}
+
+type VolumeQuotaStatus struct {
+	Vserver   string
+	Volume    string
+	Status    string
+	SubStatus string
+}
+
+func (c *Client) ListQuotas() (quotas []*Quota, err error) {
+	opts := &n.QuotaReportOptions{
+		MaxRecords: 500,
+	}
+	pageHandler := func(r n.QuotaReportPagesResponse) bool {
+		if r.Error != nil {
+			err = r.Error
+			return false
+		}
+		if err = checkResult(&r.Response.Results.ResultBase); err != nil {
+			return false
+		}
+		for _, q := range r.Response.Results.AttributesList.QuotaReportEntry {
+			quotas = append(quotas, parseQuota(q))
+		}
+		return true
+	}
+	c.QuotaReport.ReportPages(opts, pageHandler)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+func (c *Client) ListVolumeQuotaStatus() (statuses []*VolumeQuotaStatus, err error) {
+	opts := &n.QuotaStatusIterOptions{
+		MaxRecords: 500,
+	}
+	pageHandler := func(r n.QuotaStatusPagesResponse) bool {
+		if r.Error != nil {
+			err = r.Error
+			return false
+		}
+		if err = checkResult(&r.Response.Results.ResultBase); err != nil {
+			return false
+		}
+		for _, s := range r.Response.Results.AttributesList.QuotaStatusAttributes {
+			statuses = append(statuses, &VolumeQuotaStatus{
+				Vserver:   s.Vserver,
+				Volume:    s.Volume,
+				Status:    s.QuotaStatus,
+				SubStatus: s.QuotaSubStatus,
+			})
+		}
+		return true
+	}
+	c.QuotaStatus.StatusPages(opts, pageHandler)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+func parseQuota(entry n.QuotaReportEntry) *Quota {
+	return &Quota{
+		Vserver:       entry.Vserver,
+		Volume:        entry.Volume,
+		Qtree:         entry.Tree,
+		Type:          entry.QuotaType,
+		Target:        entry.QuotaTarget,
+		DiskUsed:      parseQuotaValue(entry.DiskUsed, 1024),
+		DiskLimit:     parseQuotaValue(entry.DiskLimit, 1024),
+		SoftDiskLimit: parseQuotaValue(entry.SoftDiskLimit, 1024),
+		FilesUsed:     parseQuotaValue(entry.FilesUsed, 1),
+		FileLimit:     parseQuotaValue(entry.FileLimit, 1),
+		SoftFileLimit: parseQuotaValue(entry.SoftFileLimit, 1),
+	}
+}
+
+// parseQuotaValue returns -1 for unlimited values, which are reported as "-".
+// Disk values are reported in kilobytes and scaled by the given factor.
+func parseQuotaValue(s string, factor float64) float64 {
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return -1
+	}
+	return v * factor
+}
@@ -0,0 +1,126 @@
+package netapp
+
+import (
+	"encoding/xml"
+
+	n "github.com/pepabo/go-netapp/netapp"
+)
+
+type SnapmirrorRelationship struct {
+	SourceVserver            string
+	SourceVolume             string
+	DestinationVserver       string
+	DestinationVolume        string
+	DestinationNode          string
+	RelationshipType         string
+	MirrorState              string
+	RelationshipStatus       string
+	IsHealthy                bool
+	LagTime                  float64 // -1 if not reported, e.g. for uninitialized relationships
+	LastTransferSize         float64
+	LastTransferDuration     float64
+	LastTransferEndTimestamp float64
+}
+
+// snapmirrorGetIterRequest implements snapmirror-get-iter, which is not
+// provided by the go-netapp library.
+type snapmirrorGetIterRequest struct {
+	n.Base
+	Params struct {
+		XMLName           xml.Name
+		DesiredAttributes *n.SnapmirrorInfo `xml:"desired-attributes>snapmirror-info,omitempty"`
+		MaxRecords        int               `xml:"max-records,omitempty"`
+		Tag               string            `xml:"tag,omitempty"`
+	}
+}
+
+type snapmirrorGetIterResponse struct {
+	XMLName xml.Name `xml:"netapp"`
+	Results struct {
+		n.ResultBase
+		AttributesList []snapmirrorInfo `xml:"attributes-list>snapmirror-info"`
+		NextTag        string           `xml:"next-tag"`
+	} `xml:"results"`
+}
+
+// snapmirrorInfo shadows the lag-time of go-netapp's SnapmirrorInfo, so that a
+// missing lag time can be told apart from a lag time of 0.
+type snapmirrorInfo struct {
+	n.SnapmirrorInfo
+	LagTime *int `xml:"lag-time"`
+}
+
+func (c *Client) ListSnapmirrorRelationships() (relationships []*SnapmirrorRelationship, err error) {
+	infos, err := c.listSnapmirrors()
+	if err != nil {
+		return nil, err
+	}
+	for _, info := range infos {
+		relationships = append(relationships, parseSnapmirrorRelationship(info))
+	}
+	return
+}
+
+func (c *Client) listSnapmirrors() (res []snapmirrorInfo, err error) {
+	tag := ""
+	for {
+		req := newSnapmirrorGetIterRequest(c.Snapmirror.Base, 100, tag)
+		resp := snapmirrorGetIterResponse{}
+		if err = c.doRequest(req, &resp); err != nil {
+			return nil, err
+		}
+		if err = checkResult(&resp.Results.ResultBase); err != nil {
+			return nil, err
+		}
+		res = append(res, resp.Results.AttributesList...)
+		if resp.Results.NextTag == "" {
+			return
+		}
+		tag = resp.Results.NextTag
+	}
+}
+
+func newSnapmirrorGetIterRequest(base n.Base, maxRecords int, tag string) *snapmirrorGetIterRequest {
+	req := &snapmirrorGetIterRequest{Base: base}
+	req.Params.XMLName = xml.Name{Local: "snapmirror-get-iter"}
+	req.Params.MaxRecords = maxRecords
+	req.Params.Tag = tag
+	req.Params.DesiredAttributes = &n.SnapmirrorInfo{
+		SourceVServer:            "x",
+		SourceVolume:             "x",
+		DestinationVServer:       "x",
+		DestinationVolume:        "x",
+		DestinationVolumeNode:    "x",
+		RelationshipType:         "x",
+		MirrorState:              "x",
+		RelationshipStatus:       "x",
+		IsHealthy:                true,
+		LagTime:                  1,
+		LastTransferSize:         1,
+		LastTransferDuration:     1,
+		LastTransferEndTimestamp: 1,
+	}
+	return req
+}
+
+func parseSnapmirrorRelationship(info snapmirrorInfo) *SnapmirrorRelationship {
+	lagTime := -1.0
+	if info.LagTime != nil {
+		lagTime = float64(*info.LagTime)
+	}
+	return &SnapmirrorRelationship{
+		SourceVserver:            info.SourceVServer,
+		SourceVolume:             info.SourceVolume,
+		DestinationVserver:       info.DestinationVServer,
+		DestinationVolume:        info.DestinationVolume,
+		DestinationNode:          info.DestinationVolumeNode,
+		RelationshipType:         info.RelationshipType,
+		MirrorState:              info.MirrorState,
+		RelationshipStatus:       info.RelationshipStatus,
+		IsHealthy:                info.IsHealthy,
+		LagTime:                  lagTime,
+		LastTransferSize:         float64(info.LastTransferSize),
+		LastTransferDuration:     float64(info.LastTransferDuration),
+		LastTransferEndTimestamp: float64(info.LastTransferEndTimestamp),
+	}
+}
@@ -0,0 +1,53 @@
+package netapp
+
+import (
+	n "github.com/pepabo/go-netapp/netapp"
+)
+
+// Snapshot holds the attributes of a volume snapshot. Size is the space
+// exclusively used by the snapshot in bytes.
+type Snapshot struct {
+	Name            string
+	Volume          string
+	Vserver         string
+	SnapmirrorLabel string
+	CreationTime    float64
+	Size            float64
+	Busy            bool
+	Dependency      string
+}
+
+func (c *Client) ListSnapshots() (snapshots []*Snapshot, err error) {
+	opts := &n.SnapshotOptions{
+		MaxRecords: 1000,
+	}
+	pageHandler := func(r n.SnapshotListPagesResponse) bool {
+		if r.Error != nil {
+			err = r.Error
+			return false
+		}
+		if err = checkResult(&r.Response.Results.ResultBase); err != nil {
+			return false
+		}
+		for _, s := range r.Response.Results.AttributesList.SnapshotAttributes {
+			snapshots = append(snapshots, &Snapshot{
+				Name:            s.Name,
+				Volume:          s.Volume,
+				Vserver:         s.Vserver,
+				SnapmirrorLabel: s.SnapmirrorLabel,
+				// access-time is the creation time of the snapshot
+				CreationTime: float64(s.AccessTime),
+				// total is reported in kilobytes
+				Size:       float64(s.Total) * 1024,
+				Busy:       s.Busy,
+				Dependency: s.Dependency,
+			})
+		}
+		return true
+	}
+	c.Snapshot.ListPages(opts, pageHandler)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
@@ -6,17 +6,40 @@ import (
 	n "github.com/pepabo/go-netapp/netapp"
 )
 
+type Node struct {
+	Name               string
+	Model              string
+	ProductVersion     string
+	NvramBatteryStatus string
+}
+
 func (c *Client) GetSystemVersion() (string, error) {
+	nodes, err := c.ListNodes()
+	if err != nil {
+		return "", err
+	}
+	return nodes[0].ProductVersion, nil
+}
+
+func (c *Client) ListNodes() (nodes []*Node, err error) {
 	opts := &n.NodeDetailOptions{}
 	resp, httpResp, err := c.System.List(opts)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	if httpResp.StatusCode != 200 {
-		return "", fmt.Errorf("http request failed with %v", httpResp.Status)
+		return nil, fmt.Errorf("http request failed with %v", httpResp.Status)
 	}
 	if len(resp.Results.NodeDetails) == 0 {
-		return "", fmt.Errorf("failed to get node details")
+		return nil, fmt.Errorf("failed to get node details")
+	}
+	for _, d := range resp.Results.NodeDetails {
+		nodes = append(nodes, &Node{
+			Name:               d.Name,
+			Model:              d.NodeModel,
+			ProductVersion:     d.ProductVersion,
+			NvramBatteryStatus: d.NvramBatteryStatus,
+		})
 	}
-	return resp.Results.NodeDetails[0].ProductVersion, nil
+	return
 }
@@ -0,0 +1,180 @@
+package netapp
+
+import (
+	"encoding/xml"
+	"strconv"
+
+	n "github.com/pepabo/go-netapp/netapp"
+)
+
+// VolumeFootprint is the space a volume takes in its aggregate, in bytes. The
+// components from UserData to TotalUsed come from volume-space-get-iter, the
+// footprints from volume-footprint-get-iter. The blocks footprint is split into
+// performance tier and (FabricPool) capacity tier.
+type VolumeFootprint struct {
+	Vserver                  string
+	Volume                   string
+	UserData                 float64
+	FilesystemMetadata       float64
+	Inodes                   float64
+	SnapshotReserve          float64
+	SnapshotSpill            float64
+	DedupeMetadata           float64
+	PerformanceMetadata      float64
+	TotalUsed                float64
+	GuaranteeFootprint       float64
+	BlocksFootprint          float64
+	PerformanceTierFootprint float64
+	CapacityTierFootprint    float64
+	MetadataFootprint        float64
+	DelayedFreeFootprint     float64
+	TotalFootprint           float64
+}
+
+// volumeSpaceGetIterRequest is used instead of VolumeSpace.List(), because the
+// go-netapp response type lacks the next-tag and some of the space components.
+type volumeSpaceGetIterRequest struct {
+	n.Base
+	Params struct {
+		XMLName xml.Name
+		*n.VolumeSpaceOptions
+	}
+}
+
+type volumeSpaceGetIterResponse struct {
+	XMLName xml.Name `xml:"netapp"`
+	Results struct {
+		n.ResultBase
+		AttributesList struct {
+			SpaceInfo []volumeSpaceInfo `xml:"space-info"`
+		} `xml:"attributes-list"`
+		NextTag string `xml:"next-tag"`
+	} `xml:"results"`
+}
+
+type volumeSpaceInfo struct {
+	n.VolumeSpaceInfo
+	SnapshotSpill   string `xml:"snapshot-spill"`
+	DedupeMetafiles string `xml:"dedupe-metafiles"`
+}
+
+// volume-footprint-get-iter is not implemented in go-netapp
+type volumeFootprintGetIterRequest struct {
+	n.Base
+	Params struct {
+		XMLName    xml.Name
+		MaxRecords int    `xml:"max-records,omitempty"`
+		Tag        string `xml:"tag,omitempty"`
+	}
+}
+
+type volumeFootprintGetIterResponse struct {
+	XMLName xml.Name `xml:"netapp"`
+	Results struct {
+		n.ResultBase
+		AttributesList struct {
+			FootprintInfo []volumeFootprintInfo `xml:"footprint-info"`
+		} `xml:"attributes-list"`
+		NextTag string `xml:"next-tag"`
+	} `xml:"results"`
+}
+
+type volumeFootprintInfo struct {
+	Volume                    string `xml:"volume"`
+	Vserver                   string `xml:"vserver"`
+	VolumeGuaranteeFootprint  string `xml:"volume-guarantee-footprint"`
+	VolumeBlocksFootprint     string `xml:"volume-blocks-footprint"`
+	VolumeBlocksFootprintBin0 string `xml:"volume-blocks-footprint-bin0"`
+	VolumeBlocksFootprintBin1 string `xml:"volume-blocks-footprint-bin1"`
+	FlexvolMetadataFootprint  string `xml:"flexvol-metadata-footprint"`
+	DelayedFreeFootprint      string `xml:"delayed-free-footprint"`
+	TotalFootprint            string `xml:"total-footprint"`
+}
+
+func (c *Client) ListVolumeFootprints() (footprints []*VolumeFootprint, err error) {
+	spaceInfos, err := c.listVolumeSpaces()
+	if err != nil {
+		return nil, err
+	}
+	footprintInfos, err := c.listVolumeFootprints()
+	if err != nil {
+		return nil, err
+	}
+	volumes := make(map[string]*VolumeFootprint)
+	get := func(vserver, volume string) *VolumeFootprint {
+		key := vserver + "/" + volume
+		if _, ok := volumes[key]; !ok {
+			volumes[key] = &VolumeFootprint{Vserver: vserver, Volume: volume}
+			footprints = append(footprints, volumes[key])
+		}
+		return volumes[key]
+	}
+	for _, s := range spaceInfos {
+		f := get(s.Vserver, s.Volume)
+		f.UserData, _ = strconv.ParseFloat(s.UserData, 64)
+		f.FilesystemMetadata, _ = strconv.ParseFloat(s.FilesystemMetadata, 64)
+		f.Inodes, _ = strconv.ParseFloat(s.Inodes, 64)
+		f.SnapshotReserve, _ = strconv.ParseFloat(s.SnapshotReserve, 64)
+		f.SnapshotSpill, _ = strconv.ParseFloat(s.SnapshotSpill, 64)
+		f.DedupeMetadata, _ = strconv.ParseFloat(s.DedupeMetafiles, 64)
+		f.PerformanceMetadata, _ = strconv.ParseFloat(s.PerformanceMetadata, 64)
+		f.TotalUsed = float64(s.TotalUsed)
+	}
+	for _, fp := range footprintInfos {
+		f := get(fp.Vserver, fp.Volume)
+		f.GuaranteeFootprint, _ = strconv.ParseFloat(fp.VolumeGuaranteeFootprint, 64)
+		f.BlocksFootprint, _ = strconv.ParseFloat(fp.VolumeBlocksFootprint, 64)
+		f.PerformanceTierFootprint, _ = strconv.ParseFloat(fp.VolumeBlocksFootprintBin0, 64)
+		f.CapacityTierFootprint, _ = strconv.ParseFloat(fp.VolumeBlocksFootprintBin1, 64)
+		f.MetadataFootprint, _ = strconv.ParseFloat(fp.FlexvolMetadataFootprint, 64)
+		f.DelayedFreeFootprint, _ = strconv.ParseFloat(fp.DelayedFreeFootprint, 64)
+		f.TotalFootprint, _ = strconv.ParseFloat(fp.TotalFootprint, 64)
+	}
+	return
+}
+
+func (c *Client) listVolumeSpaces() (res []volumeSpaceInfo, err error) {
+	opts := &n.VolumeSpaceOptions{MaxRecords: 500}
+	for {
+		req := &volumeSpaceGetIterRequest{Base: c.VolumeSpace.Base}
+		req.Params.XMLName = xml.Name{Local: "volume-space-get-iter"}
+		req.Params.VolumeSpaceOptions = opts
+		resp := volumeSpaceGetIterResponse{}
+		if err = c.doRequest(req, &resp); err != nil {
+			return nil, err
+		}
+		if err = checkResult(&resp.Results.ResultBase); err != nil {
+			return nil, err
+		}
+		res = append(res, resp.Results.AttributesList.SpaceInfo...)
+		if resp.Results.NextTag == "" {
+			return
+		}
+		opts = &n.VolumeSpaceOptions{
+			MaxRecords: opts.MaxRecords,
+			Tag:        resp.Results.NextTag,
+		}
+	}
+}
+
+func (c *Client) listVolumeFootprints() (res []volumeFootprintInfo, err error) {
+	tag := ""
+	for {
+		req := &volumeFootprintGetIterRequest{Base: c.VolumeSpace.Base}
+		req.Params.XMLName = xml.Name{Local: "volume-footprint-get-iter"}
+		req.Params.MaxRecords = 500
+		req.Params.Tag = tag
+		resp := volumeFootprintGetIterResponse{}
+		if err = c.doRequest(req, &resp); err != nil {
+			return nil, err
+		}
+		if err = checkResult(&resp.Results.ResultBase); err != nil {
+			return nil, err
+		}
+		res = append(res, resp.Results.AttributesList.FootprintInfo...)
+		if resp.Results.NextTag == "" {
+			return
+		}
+		tag = resp.Results.NextTag
+	}
+}
@@ -0,0 +1,105 @@
+package netapp
+
+import (
+	"encoding/xml"
+
+	n "github.com/pepabo/go-netapp/netapp"
+)
+
+type VolumeMove struct {
+	Volume                  string
+	Vserver                 string
+	SourceAggregate         string
+	DestinationAggregate    string
+	SourceNode              string
+	DestinationNode         string
+	Phase                   string
+	State                   string
+	PercentComplete         float64
+	EstimatedCompletionTime float64
+	CutoverAttempts         float64
+	CutoverAttemptedCount   float64
+	BytesSent               float64
+	BytesRemaining          float64
+	StartTime               float64
+}
+
+// volume-move-get-iter is not implemented in go-netapp
+type volumeMoveGetIterRequest struct {
+	n.Base
+	Params struct {
+		XMLName    xml.Name
+		MaxRecords int    `xml:"max-records,omitempty"`
+		Tag        string `xml:"tag,omitempty"`
+	}
+}
+
+type volumeMoveGetIterResponse struct {
+	XMLName xml.Name `xml:"netapp"`
+	Results struct {
+		n.ResultBase
+		AttributesList struct {
+			VolumeMoveInfo []volumeMoveInfo `xml:"volume-move-info"`
+		} `xml:"attributes-list"`
+		NextTag string `xml:"next-tag"`
+	} `xml:"results"`
+}
+
+type volumeMoveInfo struct {
+	Volume                  string `xml:"volume"`
+	Vserver                 string `xml:"vserver"`
+	SourceAggregate         string `xml:"source-aggregate"`
+	DestinationAggregate    string `xml:"destination-aggregate"`
+	SourceNode              string `xml:"source-node"`
+	DestinationNode         string `xml:"destination-node"`
+	Phase                   string `xml:"phase"`
+	State                   string `xml:"state"`
+	PercentComplete         int    `xml:"percent-complete"`
+	EstimatedCompletionTime int    `xml:"estimated-completion-time"`
+	CutoverAttempts         int    `xml:"cutover-attempts"`
+	CutoverAttemptedCount   int    `xml:"cutover-attempted-count"`
+	BytesSent               int    `xml:"bytes-sent"`
+	BytesRemaining          int    `xml:"bytes-remaining"`
+	StartTimestamp          int    `xml:"start-timestamp"`
+}
+
+// ListVolumeMoves returns the running and the recently finished volume moves.
+func (c *Client) ListVolumeMoves() (moves []*VolumeMove, err error) {
+	tag := ""
+	for {
+		req := &volumeMoveGetIterRequest{Base: c.Volume.Base}
+		req.Params.XMLName = xml.Name{Local: "volume-move-get-iter"}
+		req.Params.MaxRecords = 500
+		req.Params.Tag = tag
+		resp := volumeMoveGetIterResponse{}
+		if err = c.doRequest(req, &resp); err != nil {
+			return nil, err
+		}
+		if err = checkResult(&resp.Results.ResultBase); err != nil {
+			return nil, err
+		}
+		for _, m := range resp.Results.AttributesList.VolumeMoveInfo {
+			moves = append(moves, &VolumeMove{
+				Volume:                  m.Volume,
+				Vserver:                 m.Vserver,
+				SourceAggregate:         m.SourceAggregate,
+				DestinationAggregate:    m.DestinationAggregate,
+				SourceNode:              m.SourceNode,
+				DestinationNode:         m.DestinationNode,
+				Phase:                   m.Phase,
+				State:                   m.State,
+				PercentComplete:         float64(m.PercentComplete),
+				EstimatedCompletionTime: float64(m.EstimatedCompletionTime),
+				CutoverAttempts:         float64(m.CutoverAttempts),
+				CutoverAttemptedCount:   float64(m.CutoverAttemptedCount),
+				BytesSent:               float64(m.BytesSent),
+				BytesRemaining:          float64(m.BytesRemaining),
+				StartTime:               float64(m.StartTimestamp),
+			})
+		}
+		if resp.Results.NextTag == "" {
+			return
+		}
+		tag = resp.Results.NextTag
+	}
+}
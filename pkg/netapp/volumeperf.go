@@ -0,0 +1,60 @@
+package netapp
+
+import (
+	"time"
+)
+
+// VolumePerf is a sample of the raw volume perf counters. Ops, data and latency
+// counters are cumulative; latencies are in microseconds and have the
+// corresponding ops counters as base.
+type VolumePerf struct {
+	Volume       string
+	Vserver      string
+	Timestamp    time.Time
+	ReadOps      uint64
+	WriteOps     uint64
+	OtherOps     uint64
+	TotalOps     uint64
+	ReadData     uint64
+	WriteData    uint64
+	ReadLatency  uint64
+	WriteLatency uint64
+	OtherLatency uint64
+	AvgLatency   uint64
+}
+
+var volumePerfCounters = []string{
+	"vserver_name",
+	"read_ops", "write_ops", "other_ops", "total_ops",
+	"read_data", "write_data",
+	"read_latency", "write_latency", "other_latency", "avg_latency",
+}
+
+func (c *Client) ListVolumePerf() (perfs []*VolumePerf, err error) {
+	instances, err := c.ListPerfInstances("volume", volumePerfCounters)
+	if err != nil {
+		return nil, err
+	}
+	for _, instance := range instances {
+		perfs = append(perfs, parseVolumePerf(instance))
+	}
+	return
+}
+
+func parseVolumePerf(instance *PerfInstance) *VolumePerf {
+	return &VolumePerf{
+		Volume:       instance.Name,
+		Vserver:      instance.Counters["vserver_name"],
+		Timestamp:    instance.Timestamp,
+		ReadOps:      parsePerfCounter(instance, "read_ops"),
+		WriteOps:     parsePerfCounter(instance, "write_ops"),
+		OtherOps:     parsePerfCounter(instance, "other_ops"),
+		TotalOps:     parsePerfCounter(instance, "total_ops"),
+		ReadData:     parsePerfCounter(instance, "read_data"),
+		WriteData:    parsePerfCounter(instance, "write_data"),
+		ReadLatency:  parsePerfCounter(instance, "read_latency"),
+		WriteLatency: parsePerfCounter(instance, "write_latency"),
+		OtherLatency: parsePerfCounter(instance, "other_latency"),
+		AvgLatency:   parsePerfCounter(instance, "avg_latency"),
+	}
+}
@@ -0,0 +1,100 @@
+package netapp
+
+import (
+	"encoding/xml"
+	"sort"
+	"strings"
+
+	n "github.com/pepabo/go-netapp/netapp"
+)
+
+type Vserver struct {
+	Name             string
+	Type             string
+	Subtype          string
+	State            string
+	OperationalState string
+	RootVolume       string
+	IPSpace          string
+	AllowedProtocols string
+	AggregateCount   float64
+}
+
+// vserverGetIterRequest is used instead of VServer.List(), because go-netapp's
+// response type has no next-tag to page through the vservers.
+type vserverGetIterRequest struct {
+	n.Base
+	Params struct {
+		XMLName xml.Name
+		*n.VServerOptions
+	}
+}
+
+type vserverGetIterResponse struct {
+	XMLName xml.Name `xml:"netapp"`
+	Results struct {
+		n.ResultBase
+		AttributesList struct {
+			VserverInfo []n.VServerInfo `xml:"vserver-info"`
+		} `xml:"attributes-list"`
+		NextTag string `xml:"next-tag"`
+	} `xml:"results"`
+}
+
+// ListVservers returns the data vservers. The admin, node and system vservers
+// of the cluster are skipped.
+func (c *Client) ListVservers() (vservers []*Vserver, err error) {
+	vserverInfos, err := c.listVservers()
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range vserverInfos {
+		if v.VserverType != "data" {
+			continue
+		}
+		vservers = append(vservers, parseVserver(v))
+	}
+	return
+}
+
+func (c *Client) listVservers() (res []n.VServerInfo, err error) {
+	opts := &n.VServerOptions{MaxRecords: 500}
+	for {
+		req := &vserverGetIterRequest{Base: c.VServer.Base}
+		req.Params.XMLName = xml.Name{Local: "vserver-get-iter"}
+		req.Params.VServerOptions = opts
+		resp := vserverGetIterResponse{}
+		if err = c.doRequest(req, &resp); err != nil {
+			return nil, err
+		}
+		if err = checkResult(&resp.Results.ResultBase); err != nil {
+			return nil, err
+		}
+		res = append(res, resp.Results.AttributesList.VserverInfo...)
+		if resp.Results.NextTag == "" {
+			return
+		}
+		opts = &n.VServerOptions{MaxRecords: 500, Tag: resp.Results.NextTag}
+	}
+}
+
+func parseVserver(info n.VServerInfo) *Vserver {
+	v := &Vserver{
+		Name:             info.VserverName,
+		Type:             info.VserverType,
+		Subtype:          info.VserverSubtype,
+		State:            info.State,
+		OperationalState: info.OperationalState,
+		RootVolume:       info.RootVolume,
+		IPSpace:          info.Ipspace,
+	}
+	if info.AllowedProtocols != nil {
+		protocols := append([]string{}, *info.AllowedProtocols...)
+		sort.Strings(protocols)
+		v.AllowedProtocols = strings.Join(protocols, ",")
+	}
+	if info.AggregateList != nil {
+		v.AggregateCount = float64(len(*info.AggregateList))
+	}
+	return v
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/sapcc/netapp-api-exporter/pkg/collector"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// probeHandler serves /probe?target=<filer-name>&module=<collectors>. Every
+// request is gathered from a new registry, which contains only the collectors
+// of the requested filer and module. The collectors themselves are kept
+// between probes of the same target and module, since some of them compute
+// rates or counts from consecutive fetches. The filer status and the check
+// errors of a probed filer are kept separately from the ones of /metrics.
+type probeHandler struct {
+	mux     sync.Mutex
+	filers  map[string]Filer
+	probers map[string]*prober
+}
+
+type prober struct {
+	Filer
+	status          *collector.FilerStatus
+	checkErrors     *checkErrorCounters
+	collectors      []prometheus.Collector
+	volumeCollector *collector.VolumeCollector
+	mux             sync.Mutex
+}
+
+func newProbeHandler() *probeHandler {
+	return &probeHandler{
+		filers:  make(map[string]Filer),
+		probers: make(map[string]*prober),
+	}
+}
+
+// SetFilers replaces the filers which can be probed. The collectors of removed
+// or changed filers are dropped.
+func (h *probeHandler) SetFilers(ff []Filer) {
+	defer h.mux.Unlock()
+	h.mux.Lock()
+
+	h.filers = make(map[string]Filer, len(ff))
+	for _, f := range ff {
+		h.filers[f.Name] = f
+	}
+	for key, p := range h.probers {
+		if f, ok := h.filers[p.Name]; ok && f.FilerBase == p.FilerBase {
+			continue
+		}
+		if p.volumeCollector != nil {
+			p.volumeCollector.Stop()
+		}
+		delete(h.probers, key)
+	}
+}
+
+func (h *probeHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	target := r.URL.Query().Get("target")
+	if target == "" {
+		http.Error(w, "target parameter is missing", http.StatusBadRequest)
+		return
+	}
+	module, enabled, err := parseProbeModule(r.URL.Query().Get("module"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	p, err := h.getProber(target, module, enabled)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if p == nil {
+		http.Error(w, fmt.Sprintf("unknown target %q", target), http.StatusNotFound)
+		return
+	}
+
+	// concurrent probes of the same target and module would interfere in the
+	// collectors' state
+	defer p.mux.Unlock()
+	p.mux.Lock()
+
+	start := time.Now()
+	successGauge := prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "netapp_probe_success",
+		Help: "whether the filer is reachable and the collectors have been run",
+	})
+	// the probe registry is gathered after the collector registry, so that the
+	// duration includes fetching the metrics from the filer
+	durationGauge := prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "netapp_probe_duration_seconds",
+		Help: "duration in seconds of probing the filer",
+	}, func() float64 { return time.Since(start).Seconds() })
+	probeReg := prometheus.NewPedanticRegistry()
+	probeReg.MustRegister(successGauge, durationGauge)
+
+	collectorReg := prometheus.NewPedanticRegistry()
+	wrapped := prometheus.WrapRegistererWith(filerLabels(p.Filer), collectorReg)
+	l := log.WithFields(log.Fields{"Name": p.Name, "Host": p.Host, "Module": module})
+	if checkFiler(p.Filer, p.status, p.checkErrors, l) {
+		successGauge.Set(1)
+		if p.volumeCollector != nil {
+			p.volumeCollector.Refresh()
+		}
+		for _, c := range p.collectors {
+			if err := wrapped.Register(c); err != nil {
+				l.WithError(err).Error("register collector failed")
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+		}
+	}
+	// the status is gathered after the collectors, so that it includes the
+	// results of their fetches
+	statusReg := prometheus.NewPedanticRegistry()
+	err = prometheus.WrapRegistererWith(filerLabels(p.Filer), statusReg).Register(
+		collector.NewFilerStatusCollector(p.status))
+	for _, c := range p.checkErrors.collectors() {
+		if err == nil {
+			err = statusReg.Register(c)
+		}
+	}
+	if err != nil {
+		l.WithError(err).Error("register status collector failed")
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	gatherers := prometheus.Gatherers{collectorReg, statusReg, probeReg}
+	promhttp.HandlerFor(gatherers, promhttp.HandlerOpts{}).ServeHTTP(w, r)
+}
+
+// getProber returns the prober of the target and module, or nil if the target
+// is not configured.
+func (h *probeHandler) getProber(target, module string, enabled func(name string) bool) (*prober, error) {
+	defer h.mux.Unlock()
+	h.mux.Lock()
+
+	f, ok := h.filers[target]
+	if !ok {
+		return nil, nil
+	}
+	key := target + "/" + module
+	if p, ok := h.probers[key]; ok {
+		return p, nil
+	}
+	// Every prober has its own client, since the go-netapp services keep the
+	// parameters of the running request and must not be shared with /metrics
+	// or the probers of other modules.
+	f, err := NewFiler(f.FilerBase)
+	if err != nil {
+		return nil, err
+	}
+	// volumes are fetched synchronously in each probe instead of periodically
+	status := collector.NewFilerStatus()
+	collectors, volumeCollector := newCollectors(f, status, enabled, 0)
+	p := &prober{
+		Filer:           f,
+		status:          status,
+		checkErrors:     newCheckErrorCounters(),
+		collectors:      collectors,
+		volumeCollector: volumeCollector,
+	}
+	h.probers[key] = p
+	return p, nil
+}
+
+// parseProbeModule parses the comma separated list of collector names. The
+// returned module lists the names sorted and without duplicates, so that it
+// identifies the selected collectors. An empty module selects all collectors
+// which are not disabled by flags.
+func parseProbeModule(module string) (string, func(name string) bool, error) {
+	if module == "" {
+		return "", enabledByFlags, nil
+	}
+	names := make(map[string]bool)
+	for _, name := range strings.Split(module, ",") {
+		name = strings.TrimSpace(name)
+		if _, ok := collectorFlags[name]; !ok {
+			return "", nil, fmt.Errorf("unknown collector %q in module", name)
+		}
+		names[name] = true
+	}
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+	return strings.Join(sorted, ","), func(name string) bool { return names[name] }, nil
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// newTestFiler returns a filer backed by a server which answers every api
+// call with an empty result.
+func newTestFiler(t *testing.T) Filer {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/xml")
+		w.Write([]byte(`<netapp version="1.7"><results status="passed"><num-records>0</num-records></results></netapp>`))
+	}))
+	t.Cleanup(srv.Close)
+	f, err := NewFiler(FilerBase{
+		Name:             "filer",
+		Host:             strings.TrimPrefix(srv.URL, "https://"),
+		AvailabilityZone: "az",
+		Username:         "user",
+		Password:         "password",
+		Version:          netappApiVersion,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+func TestProbeModulesConcurrently(t *testing.T) {
+	f := newTestFiler(t)
+	h := newProbeHandler()
+	h.SetFilers([]Filer{f})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		for _, module := range []string{"volume,snapshot", "volume-perf,lun"} {
+			wg.Add(1)
+			go func(module string) {
+				defer wg.Done()
+				w := httptest.NewRecorder()
+				h.ServeHTTP(w, httptest.NewRequest("GET", "/probe?target=filer&module="+module, nil))
+				if w.Code != http.StatusOK {
+					t.Errorf("probe of module %s: got status %d: %s", module, w.Code, w.Body.String())
+				}
+				if !strings.Contains(w.Body.String(), "netapp_probe_success 1") {
+					t.Errorf("probe of module %s failed:\n%s", module, w.Body.String())
+				}
+			}(module)
+		}
+	}
+	wg.Wait()
+
+	if len(h.probers) != 2 {
+		t.Errorf("got %d probers, want 2", len(h.probers))
+	}
+	clients := make(map[interface{}]bool)
+	for _, p := range h.probers {
+		if p.Client == f.Client {
+			t.Errorf("prober shares the client of the registered filer")
+		}
+		clients[p.Client] = true
+	}
+	if len(clients) != len(h.probers) {
+		t.Errorf("probers share their clients")
+	}
+}
+
+func TestParseProbeModule(t *testing.T) {
+	tests := []struct {
+		module string
+		want   string
+	}{
+		{"", ""},
+		{"volume", "volume"},
+		{"volume,aggregate", "aggregate,volume"},
+		{"aggregate, volume,volume", "aggregate,volume"},
+	}
+	for _, tt := range tests {
+		got, _, err := parseProbeModule(tt.module)
+		if err != nil || got != tt.want {
+			t.Errorf("parseProbeModule(%q) = (%q, %v), want %q", tt.module, got, err, tt.want)
+		}
+	}
+	if _, _, err := parseProbeModule("volume,unknown"); err == nil {
+		t.Errorf("parseProbeModule accepts unknown collector")
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sapcc/netapp-api-exporter/pkg/collector"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// backoff between checks of an unreachable filer
+	supervisorMinBackoff = 5 * time.Second
+	supervisorMaxBackoff = 5 * time.Minute
+	// period of checking a reachable filer
+	supervisorCheckPeriod = time.Minute
+)
+
+// supervise checks the filer until it is stopped. The collectors are registered
+// once the filer is reachable, and stay registered if it becomes unreachable
+// later, so that it is exported as down. While it is down, the collectors
+// skip collecting. Unreachable filers are checked again
+// with exponential backoff. Stopping does not interrupt a running check, but
+// the collectors are not registered anymore afterwards.
+func (rf *registeredFiler) supervise(l *log.Entry) {
+	backoff := supervisorMinBackoff
+	for {
+		l.Debug("check filer")
+		wait := supervisorCheckPeriod
+		ok := checkFiler(rf.Filer, rf.status, filerCheckErrors, l)
+		// the filer may have been unregistered during the check
+		select {
+		case <-rf.stopCh:
+			return
+		default:
+		}
+		if ok {
+			backoff = supervisorMinBackoff
+			if !rf.ready {
+				l.Info("register filer")
+				if err := rf.registerCollectors(); err != nil {
+					l.Error(err)
+				}
+			}
+		} else {
+			wait = backoff
+			backoff *= 2
+			if backoff > supervisorMaxBackoff {
+				backoff = supervisorMaxBackoff
+			}
+		}
+
+		timer := time.NewTimer(withJitter(wait))
+		select {
+		case <-rf.stopCh:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// upCollector collects only while the last check of the filer was successful,
+// so that scrapes don't wait for the timeouts of an unreachable filer.
+type upCollector struct {
+	prometheus.Collector
+	status *collector.FilerStatus
+}
+
+func (c upCollector) Collect(ch chan<- prometheus.Metric) {
+	if c.status.Up() {
+		c.Collector.Collect(ch)
+	}
+}
+
+// withJitter randomizes the duration by up to 20% in either direction, so that
+// filers failing at the same time are not checked in lockstep.
+func withJitter(d time.Duration) time.Duration {
+	jitter := int64(d) / 5
+	return d + time.Duration(rand.Int63n(2*jitter+1)-jitter)
+}
+
+func init() {
+	rand.Seed(time.Now().UnixNano())
+}